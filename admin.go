@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// runAdminCommand digunakan untuk menjalankan subcommand admin create EMAIL ROLE.
+// Password dibaca dari baris pertama stdin agar tidak tersimpan di riwayat shell.
+func runAdminCommand(db *sql.DB, args []string, stdin io.Reader) error {
+	if len(args) != 3 || args[0] != "create" {
+		return errors.New("penggunaan: admin create EMAIL catalog_manager|order_manager|super_admin (password dari stdin)")
+	}
+
+	role := model.AdminRole(args[2])
+	if role != model.AdminRoleCatalogManager && role != model.AdminRoleOrderManager && role != model.AdminRoleSuperAdmin {
+		return fmt.Errorf("peran admin tidak dikenal: %s", args[2])
+	}
+
+	// pastikan migrasi sudah dijalankan agar tabel admin tersedia
+	if err := migrate(db); err != nil {
+		return err
+	}
+
+	// baca password dari stdin
+	password, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) < 8 || len(password) > 72 {
+		return errors.New("password admin harus 8 sampai 72 karakter")
+	}
+
+	// hash password untuk disimpan di database
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return err
+	}
+
+	admin := model.AdminUser{
+		ID:           uuid.New().String(),
+		Email:        strings.TrimSpace(args[1]),
+		PasswordHash: string(hash),
+		Role:         role,
+		CreatedAt:    time.Now(),
+	}
+
+	// admin dari command line tidak memiliki pelaku sehingga tidak dicatat di audit log
+	if err := model.InsertAdminUser(db, admin, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Admin %s (%s) berhasil dibuat\n", admin.Email, admin.Role)
+	return nil
+}
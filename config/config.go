@@ -0,0 +1,541 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config adalah seluruh konfigurasi aplikasi
+type Config struct {
+	Database DatabaseConfig
+	Admin    AdminConfig
+	Customer CustomerConfig
+	Order    OrderConfig
+	Passcode PasscodeConfig
+	Mail     MailConfig
+	HTTP     HTTPConfig
+}
+
+// DatabaseConfig adalah konfigurasi koneksi database
+type DatabaseConfig struct {
+	URI string
+}
+
+// AdminConfig adalah konfigurasi akses endpoint admin
+type AdminConfig struct {
+	Secret   string // kunci untuk menandatangani token admin dan pelanggan (dibedakan dengan audience)
+	TokenTTL time.Duration
+}
+
+// CustomerConfig adalah konfigurasi akun pelanggan
+type CustomerConfig struct {
+	TokenTTL time.Duration
+}
+
+// OrderConfig adalah konfigurasi pemrosesan pesanan
+type OrderConfig struct {
+	PaymentWindow            time.Duration
+	ExpiryInterval           time.Duration
+	ShippingFee              int64
+	MaxQuantity              int           // batas jumlah per produk dalam satu pesanan jika produk tidak memiliki batas sendiri
+	IdempotencyTTL           time.Duration // lama response request dengan Idempotency-Key disimpan
+	IdempotencyPurgeInterval time.Duration // interval penghapusan Idempotency-Key yang sudah kedaluwarsa
+}
+
+// PasscodeConfig adalah konfigurasi pembatasan percobaan passcode pesanan
+type PasscodeConfig struct {
+	Length           int
+	Alphabet         string
+	MaxOrderAttempts int
+	MaxIPAttempts    int
+	Backoff          time.Duration
+	Lockout          time.Duration
+	ResetTTL         time.Duration // masa berlaku token pemulihan passcode
+}
+
+// MailConfig adalah konfigurasi pengiriman email
+type MailConfig struct {
+	SMTPHost      string // wajib diisi kecuali Dir atau LogOnly diisi (mode pengembangan)
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
+	SMTPInsecure  bool // jika true, email boleh dikirim tanpa TLS ke server yang tidak mendukung STARTTLS (mode pengembangan)
+	From          string
+	Dir           string        // jika diisi, email ditulis ke direktori ini (mode pengembangan)
+	LogOnly       bool          // jika true, email hanya dicetak ke stdout termasuk token di dalamnya (mode pengembangan)
+	Locale        string        // bahasa bawaan template email
+	MaxAttempts   int           // batas percobaan pengiriman email dari antrean
+	RetryBackoff  time.Duration // jeda sebelum percobaan ulang pertama, berlipat dua setiap kegagalan
+	QueueInterval time.Duration // interval pemeriksaan antrean email
+}
+
+// HTTPConfig adalah konfigurasi server HTTP
+type HTTPConfig struct {
+	Addr            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	MaxHeaderBytes  int
+	ShutdownTimeout time.Duration
+	TrustedProxies  []string // IP/CIDR proxy yang boleh mengisi X-Forwarded-For, kosong berarti IP client diambil dari koneksi
+}
+
+// minSecretLength adalah panjang minimal kunci penandatangan token
+const minSecretLength = 32
+
+// option adalah satu nilai konfigurasi beserta nama kunci di file, environment variable, dan flag
+type option struct {
+	key   string
+	env   string
+	usage string
+	set   func(cfg *Config, value string) error
+}
+
+var options = []option{
+	{"database.uri", "DB_URI", "URI koneksi database PostgreSQL", func(cfg *Config, v string) error {
+		cfg.Database.URI = v
+		return nil
+	}},
+	{"admin.secret", "ADMIN_SECRET", "kunci rahasia untuk menandatangani token admin", func(cfg *Config, v string) error {
+		cfg.Admin.Secret = v
+		return nil
+	}},
+	{"admin.token_ttl", "ADMIN_TOKEN_TTL", "masa berlaku token login admin", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Admin.TokenTTL)
+	}},
+	{"customer.token_ttl", "CUSTOMER_TOKEN_TTL", "masa berlaku token login pelanggan", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Customer.TokenTTL)
+	}},
+	{"order.payment_window", "ORDER_PAYMENT_WINDOW", "batas waktu pembayaran pesanan", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Order.PaymentWindow)
+	}},
+	{"order.expiry_interval", "ORDER_EXPIRY_INTERVAL", "interval pemeriksaan pesanan kedaluwarsa", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Order.ExpiryInterval)
+	}},
+	{"order.shipping_fee", "SHIPPING_FEE", "ongkos kirim per pesanan", func(cfg *Config, v string) error {
+		return parseInt(v, &cfg.Order.ShippingFee)
+	}},
+	{"order.max_quantity", "ORDER_MAX_QUANTITY", "batas jumlah per produk dalam satu pesanan", func(cfg *Config, v string) error {
+		return parsePositiveInt(v, &cfg.Order.MaxQuantity)
+	}},
+	{"order.idempotency_ttl", "IDEMPOTENCY_KEY_TTL", "lama response request dengan Idempotency-Key disimpan", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Order.IdempotencyTTL)
+	}},
+	{"order.idempotency_purge_interval", "IDEMPOTENCY_PURGE_INTERVAL", "interval penghapusan Idempotency-Key yang sudah kedaluwarsa", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Order.IdempotencyPurgeInterval)
+	}},
+	{"passcode.length", "PASSCODE_LENGTH", "panjang passcode pesanan", func(cfg *Config, v string) error {
+		return parsePositiveInt(v, &cfg.Passcode.Length)
+	}},
+	{"passcode.alphabet", "PASSCODE_ALPHABET", "karakter yang digunakan untuk passcode pesanan", func(cfg *Config, v string) error {
+		cfg.Passcode.Alphabet = v
+		return nil
+	}},
+	{"passcode.max_order_attempts", "PASSCODE_MAX_ORDER_ATTEMPTS", "jumlah passcode salah per pesanan sebelum dikunci", func(cfg *Config, v string) error {
+		return parsePositiveInt(v, &cfg.Passcode.MaxOrderAttempts)
+	}},
+	{"passcode.max_ip_attempts", "PASSCODE_MAX_IP_ATTEMPTS", "jumlah passcode salah per IP sebelum dikunci", func(cfg *Config, v string) error {
+		return parsePositiveInt(v, &cfg.Passcode.MaxIPAttempts)
+	}},
+	{"passcode.backoff", "PASSCODE_BACKOFF", "jeda setelah passcode salah pertama, berlipat dua setiap kegagalan", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Passcode.Backoff)
+	}},
+	{"passcode.lockout", "PASSCODE_LOCKOUT", "lama penguncian setelah batas passcode salah tercapai", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Passcode.Lockout)
+	}},
+	{"passcode.reset_ttl", "PASSCODE_RESET_TTL", "masa berlaku token pemulihan passcode", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Passcode.ResetTTL)
+	}},
+	{"mail.smtp_host", "SMTP_HOST", "host server SMTP", func(cfg *Config, v string) error {
+		cfg.Mail.SMTPHost = v
+		return nil
+	}},
+	{"mail.smtp_port", "SMTP_PORT", "port server SMTP", func(cfg *Config, v string) error {
+		return parsePositiveInt(v, &cfg.Mail.SMTPPort)
+	}},
+	{"mail.smtp_username", "SMTP_USERNAME", "username SMTP", func(cfg *Config, v string) error {
+		cfg.Mail.SMTPUsername = v
+		return nil
+	}},
+	{"mail.smtp_password", "SMTP_PASSWORD", "password SMTP", func(cfg *Config, v string) error {
+		cfg.Mail.SMTPPassword = v
+		return nil
+	}},
+	{"mail.smtp_insecure", "SMTP_INSECURE", "izinkan SMTP tanpa TLS jika server tidak mendukung STARTTLS (mode pengembangan)", func(cfg *Config, v string) error {
+		return parseBool(v, &cfg.Mail.SMTPInsecure)
+	}},
+	{"mail.from", "MAIL_FROM", "alamat pengirim email", func(cfg *Config, v string) error {
+		cfg.Mail.From = v
+		return nil
+	}},
+	{"mail.dir", "MAIL_DIR", "direktori tujuan penulisan email (mode pengembangan)", func(cfg *Config, v string) error {
+		cfg.Mail.Dir = v
+		return nil
+	}},
+	{"mail.log_only", "MAIL_LOG_ONLY", "cetak email ke stdout tanpa mengirim (mode pengembangan)", func(cfg *Config, v string) error {
+		return parseBool(v, &cfg.Mail.LogOnly)
+	}},
+	{"mail.locale", "MAIL_LOCALE", "bahasa bawaan template email (id atau en)", func(cfg *Config, v string) error {
+		cfg.Mail.Locale = v
+		return nil
+	}},
+	{"mail.max_attempts", "MAIL_MAX_ATTEMPTS", "batas percobaan pengiriman email dari antrean", func(cfg *Config, v string) error {
+		return parsePositiveInt(v, &cfg.Mail.MaxAttempts)
+	}},
+	{"mail.retry_backoff", "MAIL_RETRY_BACKOFF", "jeda sebelum percobaan ulang pengiriman email, berlipat dua setiap kegagalan", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Mail.RetryBackoff)
+	}},
+	{"mail.queue_interval", "MAIL_QUEUE_INTERVAL", "interval pemeriksaan antrean email", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.Mail.QueueInterval)
+	}},
+	{"http.addr", "HTTP_ADDR", "alamat server", func(cfg *Config, v string) error {
+		cfg.HTTP.Addr = v
+		return nil
+	}},
+	{"http.read_timeout", "HTTP_READ_TIMEOUT", "batas waktu membaca request", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.HTTP.ReadTimeout)
+	}},
+	{"http.write_timeout", "HTTP_WRITE_TIMEOUT", "batas waktu menulis response", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.HTTP.WriteTimeout)
+	}},
+	{"http.idle_timeout", "HTTP_IDLE_TIMEOUT", "batas waktu koneksi keep-alive menganggur", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.HTTP.IdleTimeout)
+	}},
+	{"http.max_header_bytes", "HTTP_MAX_HEADER_BYTES", "ukuran maksimal header request", func(cfg *Config, v string) error {
+		var n int64
+		if err := parseInt(v, &n); err != nil {
+			return err
+		}
+		cfg.HTTP.MaxHeaderBytes = int(n)
+		return nil
+	}},
+	{"http.shutdown_timeout", "SHUTDOWN_TIMEOUT", "batas waktu menunggu request berjalan saat shutdown", func(cfg *Config, v string) error {
+		return parseDuration(v, &cfg.HTTP.ShutdownTimeout)
+	}},
+	{"http.trusted_proxies", "HTTP_TRUSTED_PROXIES", "daftar IP/CIDR proxy terpercaya, dipisahkan koma", func(cfg *Config, v string) error {
+		cfg.HTTP.TrustedProxies = parseList(v)
+		return nil
+	}},
+}
+
+// Default digunakan untuk membuat konfigurasi dengan nilai bawaan
+func Default() Config {
+	return Config{
+		Admin: AdminConfig{
+			TokenTTL: 12 * time.Hour,
+		},
+		Customer: CustomerConfig{
+			TokenTTL: 7 * 24 * time.Hour,
+		},
+		Order: OrderConfig{
+			PaymentWindow:            24 * time.Hour,
+			ExpiryInterval:           time.Minute,
+			MaxQuantity:              100,
+			IdempotencyTTL:           24 * time.Hour,
+			IdempotencyPurgeInterval: time.Hour,
+		},
+		Passcode: PasscodeConfig{
+			Length:           10,
+			Alphabet:         "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+			MaxOrderAttempts: 5,
+			MaxIPAttempts:    20,
+			Backoff:          time.Second,
+			Lockout:          15 * time.Minute,
+			ResetTTL:         30 * time.Minute,
+		},
+		Mail: MailConfig{
+			SMTPPort:      587,
+			From:          "no-reply@online-shop.local",
+			Locale:        "id",
+			MaxAttempts:   8,
+			RetryBackoff:  30 * time.Second,
+			QueueInterval: 5 * time.Second,
+		},
+		HTTP: HTTPConfig{
+			Addr:            ":8080",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			MaxHeaderBytes:  1 << 20,
+			ShutdownTimeout: 30 * time.Second,
+		},
+	}
+}
+
+// Load digunakan untuk membaca konfigurasi dari file (YAML/TOML), environment variable, lalu flag
+// dengan urutan prioritas flag > environment variable > file > nilai bawaan.
+// Argumen yang tersisa setelah flag (contoh: subcommand migrate) dikembalikan.
+func Load(args []string) (Config, []string, error) {
+	cfg := Default()
+
+	// daftarkan flag untuk setiap konfigurasi
+	fs := flag.NewFlagSet("online-shop", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path file konfigurasi (.yaml, .yml, atau .toml)")
+	flags := map[string]*string{}
+	for _, opt := range options {
+		flags[opt.key] = fs.String(flagName(opt.key), "", opt.usage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	// baca file konfigurasi jika ada
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			return Config{}, nil, err
+		}
+
+		for _, opt := range options {
+			if value, ok := values[opt.key]; ok {
+				if err := opt.set(&cfg, value); err != nil {
+					return Config{}, nil, fmt.Errorf("%s di %s: %w", opt.key, *configFile, err)
+				}
+			}
+		}
+	}
+
+	// timpa dengan environment variable
+	for _, opt := range options {
+		if value, ok := os.LookupEnv(opt.env); ok && value != "" {
+			if err := opt.set(&cfg, value); err != nil {
+				return Config{}, nil, fmt.Errorf("%s: %w", opt.env, err)
+			}
+		}
+	}
+
+	// timpa dengan flag yang diisi
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		for _, opt := range options {
+			if flagErr == nil && flagName(opt.key) == f.Name {
+				if err := opt.set(&cfg, *flags[opt.key]); err != nil {
+					flagErr = fmt.Errorf("-%s: %w", f.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return Config{}, nil, flagErr
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+// ValidateDatabase digunakan untuk memastikan konfigurasi yang dibutuhkan subcommand operasional (migrate, admin) terisi
+func (cfg Config) ValidateDatabase() error {
+	if cfg.Database.URI == "" {
+		return errors.New("DB_URI wajib diisi")
+	}
+
+	return nil
+}
+
+// Validate digunakan untuk memastikan nilai wajib untuk menjalankan server terisi dan nilai lainnya masuk akal
+func (cfg Config) Validate() error {
+	var errs []error
+
+	if err := cfg.ValidateDatabase(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Admin.Secret == "" {
+		errs = append(errs, errors.New("ADMIN_SECRET wajib diisi"))
+	} else if len(cfg.Admin.Secret) < minSecretLength {
+		errs = append(errs, fmt.Errorf("ADMIN_SECRET minimal %d karakter", minSecretLength))
+	}
+
+	if cfg.Order.ShippingFee < 0 {
+		errs = append(errs, errors.New("SHIPPING_FEE tidak boleh negatif"))
+	}
+
+	// jumlah pesanan disimpan sebagai INT
+	if cfg.Order.MaxQuantity > math.MaxInt32 {
+		errs = append(errs, fmt.Errorf("ORDER_MAX_QUANTITY maksimal %d", math.MaxInt32))
+	}
+
+	// bcrypt hanya memakai 72 byte pertama
+	if cfg.Passcode.Length < 4 || cfg.Passcode.Length > 72 {
+		errs = append(errs, errors.New("PASSCODE_LENGTH harus 4 sampai 72"))
+	}
+
+	if !validAlphabet(cfg.Passcode.Alphabet) {
+		errs = append(errs, errors.New("PASSCODE_ALPHABET harus berisi minimal 2 karakter ASCII yang dapat dicetak dan tidak berulang"))
+	}
+
+	if cfg.Passcode.Backoff > cfg.Passcode.Lockout {
+		errs = append(errs, errors.New("PASSCODE_BACKOFF tidak boleh melebihi PASSCODE_LOCKOUT"))
+	}
+
+	// email berisi token pemulihan passcode sehingga tidak boleh dicetak ke log kecuali diminta
+	if cfg.Mail.SMTPHost == "" && cfg.Mail.Dir == "" && !cfg.Mail.LogOnly {
+		errs = append(errs, errors.New("SMTP_HOST wajib diisi (atau MAIL_DIR / MAIL_LOG_ONLY=true untuk pengembangan)"))
+	}
+
+	if cfg.Mail.SMTPHost != "" && cfg.Mail.From == "" {
+		errs = append(errs, errors.New("MAIL_FROM wajib diisi jika SMTP_HOST diisi"))
+	}
+
+	if cfg.Mail.Locale != "id" && cfg.Mail.Locale != "en" {
+		errs = append(errs, errors.New("MAIL_LOCALE harus id atau en"))
+	}
+
+	if cfg.HTTP.Addr == "" {
+		errs = append(errs, errors.New("HTTP_ADDR wajib diisi"))
+	}
+
+	if cfg.HTTP.MaxHeaderBytes <= 0 {
+		errs = append(errs, errors.New("HTTP_MAX_HEADER_BYTES harus lebih dari 0"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// readFile digunakan untuk membaca file konfigurasi menjadi pasangan kunci bertitik dan nilai
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".toml":
+		err = toml.Unmarshal(content, &raw)
+	default:
+		return nil, fmt.Errorf("format file konfigurasi tidak didukung: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file konfigurasi %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", raw, values)
+
+	return values, nil
+}
+
+// flatten digunakan untuk mengubah map bersarang menjadi kunci bertitik (contoh: http.addr)
+func flatten(prefix string, raw map[string]any, values map[string]string) {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			flatten(key, nested, values)
+			continue
+		}
+
+		// daftar nilai (contoh: http.trusted_proxies) disimpan dipisahkan koma
+		if list, ok := value.([]any); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+			continue
+		}
+
+		values[key] = fmt.Sprint(value)
+	}
+}
+
+// flagName digunakan untuk mengubah kunci konfigurasi menjadi nama flag (contoh: http.read_timeout -> http-read-timeout)
+func flagName(key string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(key)
+}
+
+func parseDuration(value string, target *time.Duration) error {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	if duration <= 0 {
+		return fmt.Errorf("durasi harus lebih dari 0: %s", value)
+	}
+
+	*target = duration
+	return nil
+}
+
+func parseInt(value string, target *int64) error {
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if number < 0 {
+		return fmt.Errorf("nilai tidak boleh negatif: %s", value)
+	}
+
+	*target = number
+	return nil
+}
+
+func parsePositiveInt(value string, target *int) error {
+	var number int64
+	if err := parseInt(value, &number); err != nil {
+		return err
+	}
+
+	if number == 0 {
+		return fmt.Errorf("nilai harus lebih dari 0: %s", value)
+	}
+
+	*target = int(number)
+	return nil
+}
+
+func parseBool(value string, target *bool) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+
+	*target = b
+	return nil
+}
+
+// parseList digunakan untuk membaca daftar nilai yang dipisahkan koma, nilai kosong diabaikan
+func parseList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// validAlphabet digunakan untuk memastikan alphabet passcode berisi minimal 2 karakter ASCII unik yang dapat dicetak
+func validAlphabet(alphabet string) bool {
+	if len(alphabet) < 2 {
+		return false
+	}
+
+	seen := map[rune]bool{}
+	for _, char := range alphabet {
+		if char <= ' ' || char > '~' || seen[char] {
+			return false
+		}
+		seen[char] = true
+	}
+
+	return true
+}
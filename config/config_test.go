@@ -0,0 +1,38 @@
+package config
+
+import "testing"
+
+func TestValidateDatabaseOnlyNeedsDBURI(t *testing.T) {
+	cfg := Default()
+	cfg.Database.URI = "postgres://localhost/shop"
+
+	// subcommand operasional tidak membutuhkan ADMIN_SECRET maupun konfigurasi email
+	if err := cfg.ValidateDatabase(); err != nil {
+		t.Errorf("ValidateDatabase: %v", err)
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate seharusnya gagal tanpa ADMIN_SECRET dan SMTP_HOST")
+	}
+
+	cfg.Database.URI = ""
+	if err := cfg.ValidateDatabase(); err == nil {
+		t.Error("ValidateDatabase seharusnya gagal tanpa DB_URI")
+	}
+}
+
+func TestLoadDoesNotValidate(t *testing.T) {
+	t.Setenv("DB_URI", "postgres://localhost/shop")
+	t.Setenv("ADMIN_SECRET", "")
+	t.Setenv("SMTP_HOST", "")
+
+	cfg, args, err := Load([]string{"migrate", "status"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(args) != 2 || args[0] != "migrate" {
+		t.Errorf("args = %v, seharusnya [migrate status]", args)
+	}
+	if err := cfg.ValidateDatabase(); err != nil {
+		t.Errorf("ValidateDatabase: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/auth"
+	"github.com/fastcampus-backend-golang/online-shop/config"
+	"github.com/fastcampus-backend-golang/online-shop/middleware"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyPasswordHash digunakan saat email admin tidak ditemukan agar waktu respon login tetap sama
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), 10)
+
+func AdminLogin(admins repository.AdminRepository, cfg config.AdminConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil data login dari request body
+		var login model.AdminLogin
+		if err := c.BindJSON(&login); err != nil {
+			response.BindError(c, model.ErrInvalidLogin, err)
+			return
+		}
+
+		// ambil data admin dari database
+		admin, err := admins.SelectAdminUserByEmail(login.Email)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Error(c, err)
+			return
+		}
+
+		// cocokkan password, tetap lakukan perbandingan walaupun admin tidak ditemukan
+		hash := dummyPasswordHash
+		if err == nil {
+			hash = []byte(admin.PasswordHash)
+		}
+
+		matchErr := bcrypt.CompareHashAndPassword(hash, []byte(login.Password))
+		if err != nil || matchErr != nil || admin.IsActive == nil || !*admin.IsActive {
+			response.Error(c, model.ErrInvalidCredentials)
+			return
+		}
+
+		// terbitkan token admin
+		now := time.Now()
+		expiresAt := now.Add(cfg.TokenTTL)
+		token, err := auth.Sign(cfg.Secret, auth.Claims{
+			Subject:   admin.ID,
+			Role:      string(admin.Role),
+			Audience:  middleware.AdminAudience,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		})
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"token":     token,
+			"expiresAt": expiresAt,
+			"role":      admin.Role,
+		})
+	}
+}
+
+func ListAdminUsers(admins repository.AdminRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil data admin dari database
+		admins, err := admins.SelectAdminUser()
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		c.JSON(200, admins)
+	}
+}
+
+func CreateAdminUser(admins repository.AdminRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil data admin dari request body
+		var admin model.AdminUser
+		if err := c.BindJSON(&admin); err != nil {
+			response.BindError(c, model.ErrInvalidAdmin, err)
+			return
+		}
+
+		// password wajib diisi saat membuat admin
+		if admin.Password == "" {
+			response.Error(c, model.ErrAdminPasswordRequired)
+			return
+		}
+
+		// hash password untuk disimpan di database
+		hash, err := bcrypt.GenerateFromPassword([]byte(admin.Password), 10)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		admin.ID = uuid.New().String()
+		admin.Email = strings.TrimSpace(admin.Email)
+		admin.PasswordHash = string(hash)
+		admin.CreatedAt = time.Now()
+
+		// jangan tampilkan atau catat password, admin aktif jika tidak diisi
+		admin.Password = ""
+		if admin.IsActive == nil {
+			isActive := true
+			admin.IsActive = &isActive
+		}
+
+		// susun audit log yang disimpan bersama data admin
+		audit, err := newAuditLog(c, auditActionCreate, "admin_user", admin.ID, nil, admin)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data admin ke database
+		if err := admins.InsertAdminUser(admin, audit); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		c.JSON(201, admin)
+	}
+}
+
+func UpdateAdminUser(admins repository.AdminRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id admin dari URL
+		id := c.Param("id")
+
+		// ambil data admin dari request body
+		var adminReq model.AdminUser
+		if err := c.BindJSON(&adminReq); err != nil {
+			response.BindError(c, model.ErrInvalidAdmin, err)
+			return
+		}
+
+		// ambil data admin dari database
+		admin, err := admins.SelectAdminUserByID(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrAdminNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data sebelum diubah untuk audit log
+		before := admin
+
+		// password hanya diganti jika diisi
+		if adminReq.Password != "" {
+			hash, err := bcrypt.GenerateFromPassword([]byte(adminReq.Password), 10)
+			if err != nil {
+				response.Error(c, err)
+				return
+			}
+			admin.PasswordHash = string(hash)
+		}
+
+		admin.Email = strings.TrimSpace(adminReq.Email)
+		admin.Role = adminReq.Role
+		if adminReq.IsActive != nil {
+			admin.IsActive = adminReq.IsActive
+		}
+
+		// susun audit log, perubahan password ditandai tanpa menyimpan hash-nya
+		after := any(admin)
+		if adminReq.Password != "" {
+			after = struct {
+				model.AdminUser
+				PasswordChanged bool `json:"passwordChanged"`
+			}{admin, true}
+		}
+
+		audit, err := newAuditLog(c, auditActionUpdate, "admin_user", admin.ID, before, after)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// simpan perubahan data admin beserta audit log-nya ke database,
+		// super admin aktif terakhir diperiksa di transaction yang sama
+		if err := admins.UpdateAdminUser(admin, audit); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrAdminNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		c.JSON(200, admin)
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/middleware"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// aksi yang dicatat di audit log
+const (
+	auditActionCreate       = "create"
+	auditActionUpdate       = "update"
+	auditActionDelete       = "delete"
+	auditActionChangeStatus = "change_status"
+)
+
+// newAuditLog digunakan untuk menyusun catatan perubahan data oleh admin yang sedang login.
+// Audit log disimpan oleh repository di transaction yang sama dengan perubahannya, sehingga perubahan
+// tidak pernah tersimpan tanpa audit log.
+func newAuditLog(c *gin.Context, action, entityType, entityID string, before, after any) (*model.AuditLog, error) {
+	log := model.AuditLog{
+		ID:         uuid.New().String(),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		RequestID:  middleware.GetRequestID(c),
+		ClientIP:   c.ClientIP(),
+		CreatedAt:  time.Now(),
+	}
+
+	// isi pelaku perubahan
+	if admin, ok := middleware.CurrentAdmin(c); ok {
+		log.ActorID = &admin.ID
+		log.ActorEmail = &admin.Email
+	}
+
+	// isi data sebelum, sesudah, dan selisihnya
+	if err := log.SetAuditState(before, after); err != nil {
+		return nil, fmt.Errorf("gagal menyusun audit log %s %s %s: %w", action, entityType, entityID, err)
+	}
+
+	return &log, nil
+}
+
+func ListAuditLogs(audits repository.AuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil parameter filter dan halaman dari query URL
+		var filter model.AuditFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			response.BindError(c, model.ErrInvalidFilter, err)
+			return
+		}
+
+		// pastikan rentang waktu valid
+		if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+			response.Error(c, model.ErrInvalidTimeRange)
+			return
+		}
+
+		// atur nilai default halaman
+		if filter.Page == 0 {
+			filter.Page = 1
+		}
+		if filter.Limit == 0 {
+			filter.Limit = 20
+		}
+
+		// ambil data audit log dari database
+		logs, total, err := audits.SelectAuditLog(filter)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tentukan halaman selanjutnya jika masih ada data
+		meta := model.PageMeta{
+			Total: total,
+			Page:  filter.Page,
+			Limit: filter.Limit,
+		}
+		if filter.Page*filter.Limit < total {
+			nextPage := filter.Page + 1
+			meta.NextPage = &nextPage
+		}
+
+		// tampilkan data audit log
+		c.JSON(200, model.AuditLogList{Data: logs, Meta: meta})
+	}
+}
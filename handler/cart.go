@@ -0,0 +1,419 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/config"
+	"github.com/fastcampus-backend-golang/online-shop/i18n"
+	"github.com/fastcampus-backend-golang/online-shop/middleware"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CartTokenHeader adalah header yang berisi token keranjang tamu
+const CartTokenHeader = "X-Cart-Token"
+
+func CreateCart(carts repository.CartRepository, products repository.ProductRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now()
+		cart := model.Cart{
+			ID:        uuid.New().String(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		if customer, ok := middleware.CurrentCustomer(c); ok {
+			// pelanggan yang login hanya memiliki satu keranjang yang dipakai di semua perangkat
+			existing, err := carts.SelectCartByCustomerID(customer.ID)
+			if err == nil {
+				renderCart(c, carts, products, existing, 200)
+				return
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, err)
+				return
+			}
+
+			cart.CustomerID = &customer.ID
+		} else {
+			// keranjang tamu diakses dengan token, hanya hash-nya yang disimpan di database
+			token, err := generateToken()
+			if err != nil {
+				response.Error(c, err)
+				return
+			}
+
+			tokenHash := model.HashToken(token)
+			cart.TokenHash = &tokenHash
+			cart.Token = token
+		}
+
+		// simpan keranjang ke database
+		if err := carts.InsertCart(cart); err != nil {
+			// keranjang pelanggan sudah dibuat oleh permintaan lain
+			if errors.Is(err, model.ErrCustomerCartExists) {
+				existing, err := carts.SelectCartByCustomerID(*cart.CustomerID)
+				if err == nil {
+					renderCart(c, carts, products, existing, 200)
+					return
+				}
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		renderCart(c, carts, products, cart, 201)
+	}
+}
+
+func GetCart(carts repository.CartRepository, products repository.ProductRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil keranjang dan pastikan boleh diakses
+		cart, ok := loadCart(c, carts, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		renderCart(c, carts, products, cart, 200)
+	}
+}
+
+func AddCartItem(carts repository.CartRepository, products repository.ProductRepository, cfg config.OrderConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil keranjang dan pastikan boleh diakses
+		cart, ok := loadCart(c, carts, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		// ambil data item dari request body
+		var req model.AddCartItem
+		if err := c.BindJSON(&req); err != nil {
+			response.BindError(c, model.ErrInvalidCartItem, err)
+			return
+		}
+
+		item := model.CartItem{
+			ID:        uuid.New().String(),
+			CartID:    cart.ID,
+			ProductID: req.ProductID,
+			Quantity:  req.Quantity,
+			AddedAt:   time.Now(),
+		}
+		if req.VariantID != "" {
+			item.VariantID = &req.VariantID
+		}
+
+		// pastikan produk dan varian ada sebelum dimasukkan ke keranjang, stok diperiksa ulang saat checkout
+		priced, _, err := priceCartItems(products, []model.CartItem{item})
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		if priced[0].IssueCode != "" && priced[0].IssueCode != issueInsufficientStock {
+			field := "productId"
+			if priced[0].IssueCode != issueProductNotFound {
+				field = "variantId"
+			}
+			response.Error(c, model.ErrInvalidCartItem.WithFields(model.NewFieldError(field, priced[0].IssueCode, priced[0].Issue)))
+			return
+		}
+
+		// jumlah produk di keranjang (termasuk item yang sudah ada) tidak boleh melebihi batas per pesanan
+		items, err := carts.SelectCartItems(cart.ID)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		if err := checkCartQuantity(products, items, item.ProductID, "", item.Quantity, cfg.MaxQuantity); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// simpan item ke keranjang
+		if err := carts.UpsertCartItem(item); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		renderCart(c, carts, products, cart, 200)
+	}
+}
+
+func UpdateCartItem(carts repository.CartRepository, products repository.ProductRepository, cfg config.OrderConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil keranjang dan pastikan boleh diakses
+		cart, ok := loadCart(c, carts, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		// ambil jumlah baru dari request body
+		var req model.UpdateCartItem
+		if err := c.BindJSON(&req); err != nil {
+			response.BindError(c, model.ErrInvalidCartItem, err)
+			return
+		}
+
+		// pastikan item ada dan jumlah barunya tidak melebihi batas per pesanan
+		items, err := carts.SelectCartItems(cart.ID)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		itemID := c.Param("itemId")
+		index := slices.IndexFunc(items, func(item model.CartItem) bool { return item.ID == itemID })
+		if index < 0 {
+			response.Error(c, model.ErrCartItemNotFound)
+			return
+		}
+
+		if err := checkCartQuantity(products, items, items[index].ProductID, itemID, req.Quantity, cfg.MaxQuantity); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ubah jumlah item
+		if err := carts.UpdateCartItemQuantity(cart.ID, c.Param("itemId"), req.Quantity, time.Now()); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrCartItemNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		renderCart(c, carts, products, cart, 200)
+	}
+}
+
+func DeleteCartItem(carts repository.CartRepository, products repository.ProductRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil keranjang dan pastikan boleh diakses
+		cart, ok := loadCart(c, carts, c.Param("id"))
+		if !ok {
+			return
+		}
+
+		// hapus item dari keranjang
+		if err := carts.DeleteCartItem(cart.ID, c.Param("itemId"), time.Now()); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrCartItemNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		renderCart(c, carts, products, cart, 200)
+	}
+}
+
+// checkCartQuantity digunakan untuk memastikan jumlah produk di keranjang (seluruh variannya) setelah perubahan
+// tidak melebihi batas per pesanan. exceptItemID adalah item yang jumlahnya diganti dengan quantity,
+// kosong jika quantity ditambahkan ke keranjang.
+func checkCartQuantity(products repository.ProductRepository, items []model.CartItem, productID string, exceptItemID string, quantity int32, maxQuantity int) error {
+	total := int64(quantity)
+	for _, item := range items {
+		if item.ProductID == productID && item.ID != exceptItemID {
+			total += int64(item.Quantity)
+		}
+	}
+
+	productList, err := products.SelectProductIn([]string{productID})
+	if err != nil {
+		return err
+	}
+
+	var product model.Product
+	if len(productList) > 0 {
+		product = productList[0]
+	}
+
+	if limit := quantityLimit(product, maxQuantity); total > limit {
+		return model.ErrInvalidCartItem.WithFields(maxQuantityError("quantity", limit))
+	}
+
+	return nil
+}
+
+// loadCart digunakan untuk mengambil keranjang dan memastikan pemanggil boleh mengaksesnya: keranjang pelanggan
+// hanya untuk pelanggan tersebut, keranjang tamu dengan header X-Cart-Token. Jika gagal, response sudah dikirim.
+func loadCart(c *gin.Context, carts repository.CartRepository, id string) (model.Cart, bool) {
+	cart, err := carts.SelectCartByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(c, model.ErrCartNotFound)
+			return model.Cart{}, false
+		}
+
+		response.Error(c, err)
+		return model.Cart{}, false
+	}
+
+	// keranjang yang tidak boleh diakses dianggap tidak ada
+	allowed := false
+	if cart.CustomerID != nil {
+		customer, ok := middleware.CurrentCustomer(c)
+		allowed = ok && customer.ID == *cart.CustomerID
+	} else if token := c.GetHeader(CartTokenHeader); token != "" && cart.TokenHash != nil {
+		allowed = subtle.ConstantTimeCompare([]byte(model.HashToken(token)), []byte(*cart.TokenHash)) == 1
+	}
+
+	if !allowed {
+		response.Error(c, model.ErrCartNotFound)
+		return model.Cart{}, false
+	}
+
+	return cart, true
+}
+
+// renderCart digunakan untuk menampilkan keranjang beserta harga dan ketersediaan terbaru setiap item
+func renderCart(c *gin.Context, carts repository.CartRepository, products repository.ProductRepository, cart model.Cart, code int) {
+	items, err := carts.SelectCartItems(cart.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	cart.Items, cart.Subtotal, err = priceCartItems(products, items)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// alasan item tidak dapat dipesan ditampilkan dalam bahasa request
+	cart.Available = true
+	for i, item := range cart.Items {
+		cart.Available = cart.Available && item.Available
+		if item.IssueCode != "" {
+			cart.Items[i].Issue = i18n.Localize(c, "field."+item.IssueCode, nil, item.Issue)
+		}
+	}
+
+	c.JSON(code, cart)
+}
+
+// kode alasan item keranjang tidak dapat dipesan
+const (
+	issueProductNotFound   = "product_not_found"
+	issueVariantRequired   = "variant_required"
+	issueVariantNotFound   = "variant_not_found"
+	issueInsufficientStock = "insufficient_stock"
+)
+
+// issueMessages adalah pesan bawaan untuk setiap kode alasan, dipakai jika katalog bahasa tidak memiliki kode tersebut
+var issueMessages = map[string]string{
+	issueProductNotFound:   "Produk tidak ditemukan",
+	issueVariantRequired:   "Varian produk wajib dipilih",
+	issueVariantNotFound:   "Varian produk tidak ditemukan",
+	issueInsufficientStock: "Stok produk tidak mencukupi",
+}
+
+// priceCartItems digunakan untuk mengisi nama, harga, dan ketersediaan item keranjang dari data produk saat ini.
+// Subtotal hanya menghitung item yang dapat dipesan.
+func priceCartItems(products repository.ProductRepository, items []model.CartItem) ([]model.CartItem, int64, error) {
+	if len(items) == 0 {
+		return []model.CartItem{}, 0, nil
+	}
+
+	// daftar ID produk dan varian (tanpa duplikat)
+	ids := []string{}
+	variantIDs := []string{}
+	seenProduct := make(map[string]bool)
+	seenVariant := make(map[string]bool)
+	for _, item := range items {
+		if !seenProduct[item.ProductID] {
+			seenProduct[item.ProductID] = true
+			ids = append(ids, item.ProductID)
+		}
+
+		if item.VariantID != nil && !seenVariant[*item.VariantID] {
+			seenVariant[*item.VariantID] = true
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+	}
+
+	// ambil data produk, varian, dan produk yang memiliki varian dari database
+	productList, err := products.SelectProductIn(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	productByID := make(map[string]model.Product)
+	for _, p := range productList {
+		productByID[p.ID] = p
+	}
+
+	variants, err := products.SelectVariantIn(variantIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	variantByID := make(map[string]model.ProductVariant)
+	for _, v := range variants {
+		variantByID[v.ID] = v
+	}
+
+	hasVariants, err := products.SelectProductIDsWithVariants(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// hitung harga dan ketersediaan setiap item
+	var subtotal int64
+	priced := make([]model.CartItem, len(items))
+	for i, item := range items {
+		p, ok := productByID[item.ProductID]
+		switch {
+		case !ok:
+			item.IssueCode = issueProductNotFound
+		case item.VariantID == nil:
+			item.Name, item.Price = p.Name, p.Price
+			if hasVariants[p.ID] {
+				item.IssueCode = issueVariantRequired
+			} else if p.Stock != nil && *p.Stock < item.Quantity {
+				item.IssueCode = issueInsufficientStock
+			}
+		default:
+			item.Name, item.Price = p.Name, p.Price
+			v, ok := variantByID[*item.VariantID]
+			if !ok || v.ProductID != p.ID {
+				item.IssueCode = issueVariantNotFound
+				break
+			}
+
+			sku := v.SKU
+			item.SKU = &sku
+			item.Options = v.Options
+			item.Price = v.EffectivePrice(p)
+			if v.Stock != nil && *v.Stock < item.Quantity {
+				item.IssueCode = issueInsufficientStock
+			}
+		}
+
+		item.Total = item.Price * int64(item.Quantity)
+		item.Issue = issueMessages[item.IssueCode]
+		item.Available = item.IssueCode == ""
+		if item.Available {
+			subtotal += item.Total
+		}
+
+		priced[i] = item
+	}
+
+	return priced, subtotal, nil
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func ListCategories(categories repository.CategoryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil data kategori dari database
+		categories, err := categories.SelectCategory()
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data kategori dalam bentuk pohon
+		c.JSON(200, model.BuildCategoryTree(categories))
+	}
+}
+
+func CreateCategory(categories repository.CategoryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil data kategori dari request body
+		var category model.Category
+		if err := c.BindJSON(&category); err != nil {
+			response.BindError(c, model.ErrInvalidCategory, err)
+			return
+		}
+
+		// pastikan nama kategori diisi
+		if category.Name == "" {
+			response.Error(c, model.ErrCategoryNameRequired)
+			return
+		}
+
+		// parentId kosong berarti kategori utama
+		if category.ParentID != nil && *category.ParentID == "" {
+			category.ParentID = nil
+		}
+
+		// atur id dari UUID
+		category.ID = uuid.New().String()
+
+		// susun audit log yang disimpan bersama data kategori
+		audit, err := newAuditLog(c, auditActionCreate, "category", category.ID, nil, category)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data kategori ke database
+		if err := categories.InsertCategory(category, audit); err != nil {
+			if errors.Is(err, model.ErrCategoryNotFound) {
+				response.Error(c, model.ErrParentCategoryNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data kategori yang disimpan
+		c.JSON(201, category)
+	}
+}
+
+func UpdateCategory(categories repository.CategoryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id kategori dari URL
+		id := c.Param("id")
+
+		// ambil data kategori dari request body
+		var categoryReq model.Category
+		if err := c.BindJSON(&categoryReq); err != nil {
+			response.BindError(c, model.ErrInvalidCategory, err)
+			return
+		}
+
+		// ambil data kategori dari database
+		category, err := categories.SelectCategoryByID(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrCategoryNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data sebelum diubah untuk audit log
+		before := category
+
+		// update nama kategori jika tidak kosong
+		if categoryReq.Name != "" {
+			category.Name = categoryReq.Name
+		}
+
+		// update induk kategori jika diisi, string kosong memindahkan kategori menjadi kategori utama
+		if categoryReq.ParentID != nil {
+			category.ParentID = categoryReq.ParentID
+			if *categoryReq.ParentID == "" {
+				category.ParentID = nil
+			}
+		}
+
+		// susun audit log yang disimpan bersama perubahan kategori
+		audit, err := newAuditLog(c, auditActionUpdate, "category", category.ID, before, category)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// update data kategori ke database
+		if err := categories.UpdateCategory(category, audit); err != nil {
+			// kategori dihapus setelah dibaca
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrCategoryNotFound)
+				return
+			}
+
+			if errors.Is(err, model.ErrCategoryNotFound) {
+				response.Error(c, model.ErrParentCategoryNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data kategori yang diupdate
+		c.JSON(200, category)
+	}
+}
+
+func DeleteCategory(categories repository.CategoryRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id kategori dari URL
+		id := c.Param("id")
+
+		// ambil data kategori sebelum dihapus untuk audit log
+		before, err := categories.SelectCategoryByID(id)
+		found := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Error(c, err)
+			return
+		}
+
+		// susun audit log jika kategori memang ada
+		var audit *model.AuditLog
+		if found {
+			if audit, err = newAuditLog(c, auditActionDelete, "category", id, before, nil); err != nil {
+				response.Error(c, err)
+				return
+			}
+		}
+
+		// hapus data kategori dari database, kategori yang sudah terhapus tetap dianggap berhasil
+		if err := categories.DeleteCategory(id, audit); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data kategori yang dihapus
+		c.JSON(204, nil)
+	}
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/auth"
+	"github.com/fastcampus-backend-golang/online-shop/config"
+	"github.com/fastcampus-backend-golang/online-shop/middleware"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func RegisterCustomer(customers repository.CustomerRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil data pendaftaran dari request body
+		var register model.CustomerRegister
+		if err := c.BindJSON(&register); err != nil {
+			response.BindError(c, model.ErrInvalidRegistration, err)
+			return
+		}
+
+		// hash password untuk disimpan di database
+		hash, err := bcrypt.GenerateFromPassword([]byte(register.Password), 10)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		customer := model.Customer{
+			ID:           uuid.New().String(),
+			Email:        strings.TrimSpace(register.Email),
+			Name:         strings.TrimSpace(register.Name),
+			PasswordHash: string(hash),
+			CreatedAt:    time.Now(),
+		}
+
+		// simpan data pelanggan ke database
+		if err := customers.InsertCustomer(customer); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		c.JSON(201, customer)
+	}
+}
+
+func CustomerLogin(customers repository.CustomerRepository, secret string, cfg config.CustomerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil data login dari request body
+		var login model.CustomerLogin
+		if err := c.BindJSON(&login); err != nil {
+			response.BindError(c, model.ErrInvalidLogin, err)
+			return
+		}
+
+		// ambil data pelanggan dari database
+		customer, err := customers.SelectCustomerByEmail(strings.TrimSpace(login.Email))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Error(c, err)
+			return
+		}
+
+		// cocokkan password, tetap lakukan perbandingan walaupun pelanggan tidak ditemukan
+		hash := dummyPasswordHash
+		if err == nil {
+			hash = []byte(customer.PasswordHash)
+		}
+
+		matchErr := bcrypt.CompareHashAndPassword(hash, []byte(login.Password))
+		if err != nil || matchErr != nil {
+			response.Error(c, model.ErrInvalidCredentials)
+			return
+		}
+
+		// terbitkan token pelanggan
+		now := time.Now()
+		expiresAt := now.Add(cfg.TokenTTL)
+		token, err := auth.Sign(secret, auth.Claims{
+			Subject:   customer.ID,
+			Audience:  middleware.CustomerAudience,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		})
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"token":     token,
+			"expiresAt": expiresAt,
+			"customer":  customer,
+		})
+	}
+}
+
+func GetCurrentCustomer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil pelanggan yang sedang login
+		customer, ok := middleware.CurrentCustomer(c)
+		if !ok {
+			response.Error(c, model.ErrUnauthorized)
+			return
+		}
+
+		c.JSON(200, customer)
+	}
+}
+
+func ListCustomerOrders(orders repository.OrderRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil pelanggan yang sedang login
+		customer, ok := middleware.CurrentCustomer(c)
+		if !ok {
+			response.Error(c, model.ErrUnauthorized)
+			return
+		}
+
+		// ambil parameter halaman dari query
+		var filter model.OrderFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			response.BindError(c, model.ErrInvalidFilter, err)
+			return
+		}
+
+		// atur nilai default halaman
+		if filter.Page == 0 {
+			filter.Page = 1
+		}
+		if filter.Limit == 0 {
+			filter.Limit = 20
+		}
+
+		// ambil data pesanan pelanggan dari database
+		list, total, err := orders.SelectOrderByCustomerID(customer.ID, filter.Page, filter.Limit)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tentukan halaman selanjutnya jika masih ada data
+		meta := model.PageMeta{
+			Total: total,
+			Page:  filter.Page,
+			Limit: filter.Limit,
+		}
+		if filter.Page*filter.Limit < total {
+			nextPage := filter.Page + 1
+			meta.NextPage = &nextPage
+		}
+
+		// tampilkan data pesanan
+		c.JSON(200, model.OrderList{Data: list, Meta: meta})
+	}
+}
+
+func AttachCustomerOrder(orders repository.OrderRepository, guard *PasscodeGuard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil pelanggan yang sedang login
+		customer, ok := middleware.CurrentCustomer(c)
+		if !ok {
+			response.Error(c, model.ErrUnauthorized)
+			return
+		}
+
+		// ambil id pesanan dan passcode dari request body
+		var attach model.AttachOrder
+		if err := c.BindJSON(&attach); err != nil {
+			response.BindError(c, model.ErrInvalidOrder, err)
+			return
+		}
+
+		// ambil data order dari database
+		order, err := orders.SelectOrderByID(attach.OrderID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrOrderNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// passcode membuktikan pelanggan adalah pemilik pesanan
+		if !guard.verify(c, order, attach.Passcode) {
+			return
+		}
+
+		// tautkan pesanan ke akun pelanggan
+		if err := orders.AttachOrderToCustomer(order.ID, customer.ID); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// jangan tampilkan passcode
+		order.Passcode = nil
+		order.CustomerID = &customer.ID
+
+		c.JSON(200, order)
+	}
+}
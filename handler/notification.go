@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/mail"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/google/uuid"
+)
+
+// nama template email untuk setiap kejadian pesanan
+const (
+	emailOrderPlaced    = "order_placed"
+	emailOrderPaid      = "order_paid"
+	emailOrderShipped   = "order_shipped"
+	emailOrderCancelled = "order_cancelled"
+	emailPasscodeReset  = "passcode_reset"
+)
+
+// orderStatusEmails adalah template email untuk perubahan status pesanan oleh admin
+var orderStatusEmails = map[model.OrderStatus]string{
+	model.OrderStatusShipped:   emailOrderShipped,
+	model.OrderStatusCancelled: emailOrderCancelled,
+}
+
+// OrderNotifier digunakan untuk membuat email notifikasi pesanan (termasuk pemulihan passcode) untuk antrean.
+// Email notifikasi pesanan dimasukkan ke antrean di transaction yang sama dengan perubahan pesanannya dan dikirim
+// oleh worker sehingga gangguan server email tidak menggagalkan request.
+type OrderNotifier struct {
+	emails    repository.EmailRepository
+	templates *mail.Templates
+	locale    string
+}
+
+// NewOrderNotifier digunakan untuk membuat OrderNotifier
+func NewOrderNotifier(emails repository.EmailRepository, templates *mail.Templates, locale string) *OrderNotifier {
+	return &OrderNotifier{emails: emails, templates: templates, locale: locale}
+}
+
+// orderEmailData adalah data yang dapat dipakai di template email pesanan
+type orderEmailData struct {
+	Order model.Order
+	Note  string
+}
+
+// passcodeResetEmailData adalah data yang dapat dipakai di template email pemulihan passcode
+type passcodeResetEmailData struct {
+	Order     model.Order
+	Token     string
+	ExpiresAt time.Time
+}
+
+// orderEmail digunakan untuk membuat email notifikasi pesanan dalam bahasa yang dipilih saat checkout
+// (bahasa bawaan untuk pesanan lama). Email diberikan ke repository pesanan agar masuk antrean bersama perubahannya.
+// Jika email gagal dibuat, kegagalan hanya dicatat di log dan hasilnya nil.
+func (n *OrderNotifier) orderEmail(order model.Order, template string, note string) *model.Email {
+	if n == nil {
+		return nil
+	}
+
+	locale := order.Locale
+	if locale == "" {
+		locale = n.locale
+	}
+
+	return n.render(locale, order, template, orderEmailData{Order: order, Note: note})
+}
+
+// notifyPasscodeReset digunakan untuk memasukkan email berisi token pemulihan passcode ke antrean
+// dalam bahasa yang diminta (bahasa bawaan jika template tidak tersedia). Kegagalan hanya dicatat di log.
+func (n *OrderNotifier) notifyPasscodeReset(order model.Order, locale string, token string, expiresAt time.Time) {
+	if n == nil {
+		return
+	}
+
+	email := n.render(locale, order, emailPasscodeReset, passcodeResetEmailData{Order: order, Token: token, ExpiresAt: expiresAt})
+	if email == nil {
+		return
+	}
+
+	if err := n.emails.InsertEmail(*email); err != nil {
+		fmt.Printf("Gagal memasukkan email %s pesanan %s ke antrean: %v\n", emailPasscodeReset, order.ID, err)
+	}
+}
+
+// render digunakan untuk membuat email antrean dari template
+func (n *OrderNotifier) render(locale string, order model.Order, template string, data any) *model.Email {
+	message, err := n.templates.Render(locale, template, order.Email, data)
+	if err != nil {
+		fmt.Printf("Gagal membuat email %s pesanan %s: %v\n", template, order.ID, err)
+		return nil
+	}
+
+	now := time.Now()
+	email := model.Email{
+		ID:            uuid.New().String(),
+		To:            message.To,
+		Subject:       message.Subject,
+		Text:          message.Text,
+		HTML:          message.HTML,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+
+	return &email
+}
@@ -1,59 +1,156 @@
 package handler
 
 import (
+	"crypto/rand"
 	"database/sql"
-	"math/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"time"
 
+	"github.com/fastcampus-backend-golang/online-shop/config"
+	"github.com/fastcampus-backend-golang/online-shop/i18n"
+	"github.com/fastcampus-backend-golang/online-shop/middleware"
 	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func CheckoutOrder(db *sql.DB) gin.HandlerFunc {
+func CheckoutOrder(products repository.ProductRepository, orders repository.OrderRepository, carts repository.CartRepository, notifier *OrderNotifier, cfg config.OrderConfig, passcodeCfg config.PasscodeConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// ambil data pesanan dari request body
 		var checkoutOrder model.Checkout
 		if err := c.BindJSON(&checkoutOrder); err != nil {
-			c.JSON(400, gin.H{"error": "Data pesanan tidak valid"})
+			response.BindError(c, model.ErrInvalidOrder, err)
 			return
 		}
 
-		// daftar ID produk dan jumlah pesanan
-		ids := make([]string, len(checkoutOrder.Products))
-		orderQuantity := make(map[string]int32)
-		for i, p := range checkoutOrder.Products {
-			ids[i] = p.ID
-			orderQuantity[p.ID] = p.Quantity
+		// produk diambil dari keranjang atau dari request body, tidak boleh keduanya
+		if checkoutOrder.CartID != "" && len(checkoutOrder.Products) > 0 {
+			response.Error(c, model.ErrCartOrProducts)
+			return
+		}
+
+		var cartCheckout *model.CartCheckout
+		if checkoutOrder.CartID != "" {
+			// ambil keranjang dan pastikan boleh diakses
+			cart, ok := loadCart(c, carts, checkoutOrder.CartID)
+			if !ok {
+				return
+			}
+
+			items, err := carts.SelectCartItems(cart.ID)
+			if err != nil {
+				response.Error(c, err)
+				return
+			}
+
+			// harga dan stok dihitung ulang di bawah seperti checkout biasa
+			for _, item := range items {
+				line := model.ProductQuantity{ID: item.ProductID, Quantity: item.Quantity}
+				if item.VariantID != nil {
+					line.VariantID = *item.VariantID
+				}
+				checkoutOrder.Products = append(checkoutOrder.Products, line)
+			}
+
+			// item yang dibaca dihapus dari keranjang bersama pembuatan pesanan
+			cartCheckout = &model.CartCheckout{CartID: cart.ID, Items: items}
+		}
+
+		// minimal 1 produk
+		if len(checkoutOrder.Products) == 0 {
+			response.Error(c, model.ErrEmptyOrder)
+			return
+		}
+
+		// validasi setiap baris lalu gabungkan baris dengan produk dan varian yang sama
+		lines, fieldErrors := mergeCheckoutLines(checkoutOrder.Products)
+		if len(fieldErrors) > 0 {
+			response.Error(c, model.ErrInvalidOrder.WithFields(fieldErrors...))
+			return
+		}
+
+		// daftar ID produk dan varian yang dipesan (tanpa duplikat)
+		ids := []string{}
+		variantIDs := []string{}
+		seenProduct := make(map[string]bool)
+		seenVariant := make(map[string]bool)
+		for _, line := range lines {
+			if !seenProduct[line.ID] {
+				seenProduct[line.ID] = true
+				ids = append(ids, line.ID)
+			}
+
+			if line.VariantID != "" && !seenVariant[line.VariantID] {
+				seenVariant[line.VariantID] = true
+				variantIDs = append(variantIDs, line.VariantID)
+			}
 		}
 
 		// ambil data produk dari database
-		product, err := model.SelectProductIn(db, ids)
+		product, err := products.SelectProductIn(ids)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		productByID := make(map[string]model.Product)
+		for _, p := range product {
+			productByID[p.ID] = p
+		}
+
+		// ambil data varian dari database
+		variants, err := products.SelectVariantIn(variantIDs)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		variantByID := make(map[string]model.ProductVariant)
+		for _, v := range variants {
+			variantByID[v.ID] = v
+		}
+
+		// ambil produk yang memiliki varian
+		hasVariants, err := products.SelectProductIDsWithVariants(ids)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+			response.Error(c, err)
 			return
 		}
 
-		// pastikan semua produk ada
-		if len(product) != len(checkoutOrder.Products) {
-			c.JSON(400, gin.H{"error": "Produk tidak ditemukan"})
+		// pastikan produk dan varian ada serta jumlahnya tidak melebihi batas per pesanan
+		fieldErrors = validateCheckoutLines(lines, productByID, variantByID, hasVariants, cfg.MaxQuantity)
+		if len(fieldErrors) > 0 {
+			response.Error(c, model.ErrInvalidOrder.WithFields(fieldErrors...))
 			return
 		}
 
 		// siapkan passcode
-		passcode := generatePasscode(5)
+		passcode, err := generatePasscode(passcodeCfg.Length, passcodeCfg.Alphabet)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
 
 		// hash passcode untuk disimpan di database
 		hashPasscode, err := bcrypt.GenerateFromPassword([]byte(passcode), 10)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+			response.Error(c, err)
 			return
 		}
 
 		// ubah menjadi string
 		hashedPasscodeStr := string(hashPasscode)
 
+		// tentukan batas waktu pembayaran
+		createdAt := time.Now()
+		expiresAt := createdAt.Add(cfg.PaymentWindow)
+
 		// siapkan data order dan detail order
 		order := model.Order{
 			ID:         uuid.New().String(),
@@ -61,33 +158,76 @@ func CheckoutOrder(db *sql.DB) gin.HandlerFunc {
 			Address:    checkoutOrder.Address,
 			Passcode:   &hashedPasscodeStr,
 			GrandTotal: 0,
+			Status:     model.OrderStatusPending,
+			CreatedAt:  createdAt,
+			ExpiresAt:  &expiresAt,
+			Locale:     i18n.Language(c),
+		}
+
+		// tautkan pesanan ke akun jika pelanggan sudah login
+		if customer, ok := middleware.CurrentCustomer(c); ok {
+			order.CustomerID = &customer.ID
 		}
 
 		details := []model.OrderDetail{}
 
 		// buat detail dan hitung total harga
-		for _, p := range product {
-			// hitung total untuk produk ini
-			total := p.Price * int64(orderQuantity[p.ID])
+		for _, line := range lines {
+			p := productByID[line.ID]
 
-			// tambahkan detail pesanan
+			// tambahkan detail pesanan dengan harga produk
 			detail := model.OrderDetail{
 				ID:        uuid.New().String(),
 				OrderID:   order.ID,
 				ProductID: p.ID,
-				Quantity:  orderQuantity[p.ID],
+				Quantity:  int32(line.quantity),
 				Price:     p.Price,
-				Total:     total,
 			}
+
+			// catat varian yang dibeli beserta harganya
+			if line.VariantID != "" {
+				v := variantByID[line.VariantID]
+				variantID, sku := v.ID, v.SKU
+				detail.VariantID = &variantID
+				detail.SKU = &sku
+				detail.Options = v.Options
+				detail.Price = v.EffectivePrice(p)
+			}
+
+			// hitung total untuk baris ini
+			detail.Total = detail.Price * int64(detail.Quantity)
 			details = append(details, detail)
 
-			// tambahkan total harga pesanan
-			order.GrandTotal += total
+			// tambahkan subtotal harga pesanan
+			order.Subtotal += detail.Total
 		}
 
-		// simpan data order dan detail order ke database
-		if err := model.CreateOrder(db, order, details); err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+		// terapkan kode promo jika diisi
+		order.ShippingFee = cfg.ShippingFee
+		discounts := []model.OrderDiscount{}
+		if checkoutOrder.PromoCode != "" {
+			discount, err := orders.ApplyPromoCode(checkoutOrder.PromoCode, order.Email, details, cfg.ShippingFee, createdAt)
+			if err != nil {
+				response.Error(c, checkoutPromoError(err))
+				return
+			}
+
+			discount.ID = uuid.New().String()
+			discount.OrderID = order.ID
+			discounts = append(discounts, discount)
+			order.DiscountTotal += discount.Amount
+		}
+
+		// hitung total akhir, potongan tidak boleh membuat total menjadi negatif
+		order.GrandTotal = max(order.Subtotal+order.ShippingFee-order.DiscountTotal, 0)
+
+		// siapkan email pesanan dibuat (sebelum passcode asli diisi agar tidak ikut di email)
+		email := notifier.orderEmail(order, emailOrderPlaced, "")
+
+		// simpan data order, detail order, dan email ke database
+		if err := orders.CreateOrder(order, details, discounts, cartCheckout, email); err != nil {
+			// error stok menyertakan daftar produk yang stoknya tidak mencukupi
+			response.Error(c, checkoutPromoError(err))
 			return
 		}
 
@@ -97,8 +237,9 @@ func CheckoutOrder(db *sql.DB) gin.HandlerFunc {
 
 		// buat response
 		response := model.OrderWithDetail{
-			Order:  order,
-			Detail: details,
+			Order:     order,
+			Detail:    details,
+			Discounts: discounts,
 		}
 
 		// tampilkan data order yang disimpan
@@ -106,7 +247,7 @@ func CheckoutOrder(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
-func ConfirmOrder(db *sql.DB) gin.HandlerFunc {
+func ConfirmOrder(orders repository.OrderRepository, guard *PasscodeGuard, notifier *OrderNotifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// ambil id order dari URL
 		id := c.Param("id")
@@ -114,57 +255,49 @@ func ConfirmOrder(db *sql.DB) gin.HandlerFunc {
 		// baca request body
 		var confirm model.Confirm
 		if err := c.BindJSON(&confirm); err != nil {
-			c.JSON(400, gin.H{"error": "Data konfirmasi tidak valid"})
+			response.BindError(c, model.ErrInvalidConfirmation, err)
 			return
 		}
 
 		// ambil data order dari database
-		order, err := model.SelectOrderByID(db, id)
+		order, err := orders.SelectOrderByID(id)
 		if err != nil {
 			if err == sql.ErrNoRows {
-				c.JSON(404, gin.H{"error": "Pesanan tidak ditemukan"})
+				response.Error(c, model.ErrOrderNotFound)
 				return
 			}
 
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+			response.Error(c, err)
 			return
 		}
 
-		// pastikan passcode tidak kosong agar tidak terjadi panic
-		if order.Passcode == nil {
-			c.JSON(500, gin.H{"error": "Data pesanan tidak valid"})
-			return
-		}
-
-		// cocokkan passcode
-		if err := bcrypt.CompareHashAndPassword([]byte(*order.Passcode), []byte(confirm.Passcode)); err != nil {
-			c.JSON(401, gin.H{"error": "Passcode tidak valid"})
+		// cocokkan passcode dengan pembatasan percobaan yang gagal
+		if !guard.verify(c, order, confirm.Passcode) {
 			return
 		}
 
 		// izinkan hanya untuk pesanan yang belum dibayar
 		if order.PaidAt != nil {
-			c.JSON(400, gin.H{"error": "Pesanan sudah dibayar"})
+			response.Error(c, model.ErrOrderAlreadyPaid)
 			return
 		}
 
-		// cocokkan jumlah pembayaran
-		if order.GrandTotal != confirm.Amount {
-			c.JSON(400, gin.H{"error": "Jumlah pembayaran tidak sesuai"})
+		// tolak pesanan yang sudah melewati batas waktu pembayaran
+		currentTime := time.Now()
+		if order.Status == model.OrderStatusExpired || (order.ExpiresAt != nil && currentTime.After(*order.ExpiresAt)) {
+			response.Error(c, model.ErrOrderExpired)
 			return
 		}
 
-		// update status pesanan
-		currentTime := time.Now()
-		if err := model.UpdateOrderStatus(db, id, confirm, currentTime); err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+		// izinkan hanya untuk pesanan yang masih menunggu pembayaran
+		if order.Status != model.OrderStatusPending {
+			response.Error(c, model.ErrOrderNotPayable)
 			return
 		}
 
-		// ambil detail order dari database
-		details, err := model.SelectOrderDetailByOrderID(db, id)
-		if err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+		// cocokkan jumlah pembayaran
+		if order.GrandTotal != confirm.Amount {
+			response.Error(c, model.ErrPaymentAmountMismatch)
 			return
 		}
 
@@ -172,15 +305,51 @@ func ConfirmOrder(db *sql.DB) gin.HandlerFunc {
 		// cukup ditampilkan ketika pesanan dibuat
 		order.Passcode = nil
 
-		// update response dengan data konfirmasi pesanan
+		// data order setelah dikonfirmasi untuk email dan response
+		order.Status = model.OrderStatusPaid
 		order.PaidAt = &currentTime
 		order.PaidBank = &confirm.Bank
 		order.PaidAccountNumber = &confirm.AccountNumber
 
+		// update status pesanan dan masukkan email pembayaran diterima ke antrean
+		email := notifier.orderEmail(order, emailOrderPaid, "")
+		if err := orders.UpdateOrderStatus(id, confirm, currentTime, email); err != nil {
+			if errors.Is(err, model.ErrInvalidStatusTransition) {
+				response.Error(c, model.ErrOrderNotPayable)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// ambil potongan order dari database
+		discounts, err := orders.SelectOrderDiscountByOrderID(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ambil riwayat status dari database
+		history, err := orders.SelectOrderStatusHistory(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ambil detail order dari database
+		details, err := orders.SelectOrderDetailByOrderID(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
 		// buat response
 		response := model.OrderWithDetail{
-			Order:  order,
-			Detail: details,
+			Order:     order,
+			Detail:    details,
+			Discounts: discounts,
+			History:   history,
 		}
 
 		// tampilkan data order yang sudah dikonfirmasi
@@ -188,7 +357,7 @@ func ConfirmOrder(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
-func GetOrder(db *sql.DB) gin.HandlerFunc {
+func GetOrder(orders repository.OrderRepository, guard *PasscodeGuard) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// ambil id order dari URL
 		id := c.Param("id")
@@ -197,33 +366,40 @@ func GetOrder(db *sql.DB) gin.HandlerFunc {
 		passcode := c.Query("passcode")
 
 		// ambil data order dari database
-		order, err := model.SelectOrderByID(db, id)
+		order, err := orders.SelectOrderByID(id)
 		if err != nil {
 			if err == sql.ErrNoRows {
-				c.JSON(404, gin.H{"error": "Pesanan tidak ditemukan"})
+				response.Error(c, model.ErrOrderNotFound)
 				return
 			}
 
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+			response.Error(c, err)
 			return
 		}
 
-		// pastikan passcode tidak kosong agar tidak terjadi panic
-		if order.Passcode == nil {
-			c.JSON(500, gin.H{"error": "Data pesanan tidak valid"})
+		// cocokkan passcode dengan pembatasan percobaan yang gagal
+		if !guard.verify(c, order, passcode) {
 			return
 		}
 
-		// cocokkan passcode
-		if err := bcrypt.CompareHashAndPassword([]byte(*order.Passcode), []byte(passcode)); err != nil {
-			c.JSON(401, gin.H{"error": "Passcode tidak valid"})
+		// ambil detail order dari database
+		details, err := orders.SelectOrderDetailByOrderID(id)
+		if err != nil {
+			response.Error(c, err)
 			return
 		}
 
-		// ambil detail order dari database
-		details, err := model.SelectOrderDetailByOrderID(db, id)
+		// ambil potongan order dari database
+		discounts, err := orders.SelectOrderDiscountByOrderID(id)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+			response.Error(c, err)
+			return
+		}
+
+		// ambil riwayat status dari database
+		history, err := orders.SelectOrderStatusHistory(id)
+		if err != nil {
+			response.Error(c, err)
 			return
 		}
 
@@ -233,8 +409,10 @@ func GetOrder(db *sql.DB) gin.HandlerFunc {
 
 		// buat response
 		response := model.OrderWithDetail{
-			Order:  order,
-			Detail: details,
+			Order:     order,
+			Detail:    details,
+			Discounts: discounts,
+			History:   history,
 		}
 
 		// tampilkan data order
@@ -242,14 +420,404 @@ func GetOrder(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
-func generatePasscode(length int) string {
-	charSet := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
-	randomGen := rand.New(rand.NewSource(time.Now().UnixNano()))
+func CancelOrder(orders repository.OrderRepository, guard *PasscodeGuard, notifier *OrderNotifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id order dari URL
+		id := c.Param("id")
+
+		// baca request body
+		var cancel model.Cancel
+		if err := c.BindJSON(&cancel); err != nil {
+			response.BindError(c, model.ErrInvalidCancellation, err)
+			return
+		}
+
+		// ambil data order dari database
+		order, err := orders.SelectOrderByID(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrOrderNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// cocokkan passcode dengan pembatasan percobaan yang gagal
+		if !guard.verify(c, order, cancel.Passcode) {
+			return
+		}
+
+		// izinkan hanya untuk pesanan yang belum dibayar
+		if order.Status != model.OrderStatusPending {
+			response.Error(c, model.ErrOrderNotCancellable)
+			return
+		}
+
+		// jangan tampilkan passcode
+		order.Passcode = nil
+
+		// data order setelah dibatalkan untuk email dan response
+		currentTime := time.Now()
+		order.Status = model.OrderStatusCancelled
+		order.CancelledAt = &currentTime
+		order.CancelReason = &cancel.Reason
+
+		// batalkan pesanan dan masukkan email pesanan dibatalkan ke antrean
+		email := notifier.orderEmail(order, emailOrderCancelled, cancel.Reason)
+		if err := orders.CancelUnpaidOrder(id, cancel.Reason, currentTime, email); err != nil {
+			if errors.Is(err, model.ErrInvalidStatusTransition) {
+				response.Error(c, model.ErrOrderNotCancellable)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// ambil detail order dari database
+		details, err := orders.SelectOrderDetailByOrderID(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ambil potongan order dari database
+		discounts, err := orders.SelectOrderDiscountByOrderID(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ambil riwayat status dari database
+		history, err := orders.SelectOrderStatusHistory(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// buat response
+		response := model.OrderWithDetail{
+			Order:     order,
+			Detail:    details,
+			Discounts: discounts,
+			History:   history,
+		}
+
+		// tampilkan data order yang dibatalkan
+		c.JSON(200, response)
+	}
+}
+
+func RotatePasscode(orders repository.OrderRepository, guard *PasscodeGuard, passcodeCfg config.PasscodeConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id order dari URL
+		id := c.Param("id")
+
+		// ambil passcode saat ini dari request body
+		var rotate model.RotatePasscode
+		if err := c.BindJSON(&rotate); err != nil {
+			response.BindError(c, model.ErrInvalidPasscodeRotation, err)
+			return
+		}
+
+		// ambil data order dari database
+		order, err := orders.SelectOrderByID(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrOrderNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// cocokkan passcode dengan pembatasan percobaan yang gagal
+		if !guard.verify(c, order, rotate.Passcode) {
+			return
+		}
+
+		// buat passcode baru
+		passcode, err := generatePasscode(passcodeCfg.Length, passcodeCfg.Alphabet)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// hash passcode untuk disimpan di database
+		hashPasscode, err := bcrypt.GenerateFromPassword([]byte(passcode), 10)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ganti passcode hanya jika belum diganti oleh permintaan lain
+		if err := orders.UpdateOrderPasscode(id, *order.Passcode, string(hashPasscode)); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan passcode baru yang tidak dihash
+		c.JSON(200, gin.H{"id": id, "passcode": passcode})
+	}
+}
+
+func ChangeOrderStatus(orders repository.OrderRepository, notifier *OrderNotifier, status model.OrderStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id order dari URL
+		id := c.Param("id")
+
+		// baca request body (opsional, berisi catatan perubahan)
+		var change model.StatusChange
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&change); err != nil {
+				response.BindError(c, model.ErrInvalidStatusChange, err)
+				return
+			}
+		}
+
+		// ambil data order sebelum diubah untuk audit log
+		before, err := orders.SelectOrderByID(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrOrderNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+		before.Passcode = nil
+
+		// susun data order setelah status dipindahkan untuk audit log
+		changedAt := time.Now()
+		after := before
+		after.Status = status
+		if status == model.OrderStatusCancelled {
+			after.CancelledAt = &changedAt
+			if change.Note != "" {
+				after.CancelReason = &change.Note
+			}
+		}
+
+		audit, err := newAuditLog(c, auditActionChangeStatus, "order", id, before, after)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// siapkan email ke pelanggan jika status ini memiliki notifikasi
+		var email *model.Email
+		if template, ok := orderStatusEmails[status]; ok {
+			email = notifier.orderEmail(after, template, change.Note)
+		}
+
+		// pindahkan status pesanan lalu simpan audit log dan email-nya
+		if err := orders.ChangeOrderStatus(id, status, change.Note, changedAt, audit, email); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrOrderNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// ambil data order terbaru dari database
+		order, err := orders.SelectOrderByID(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ambil detail order dari database
+		details, err := orders.SelectOrderDetailByOrderID(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ambil potongan order dari database
+		discounts, err := orders.SelectOrderDiscountByOrderID(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ambil riwayat status dari database
+		history, err := orders.SelectOrderStatusHistory(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// jangan tampilkan passcode
+		order.Passcode = nil
+
+		// buat response
+		response := model.OrderWithDetail{
+			Order:     order,
+			Detail:    details,
+			Discounts: discounts,
+			History:   history,
+		}
+
+		// tampilkan data order yang diubah statusnya
+		c.JSON(200, response)
+	}
+}
+
+// checkoutLine adalah baris pesanan setelah baris dengan produk dan varian yang sama digabung
+type checkoutLine struct {
+	model.ProductQuantity
+	index    int   // posisi baris pertama di request, untuk menyebut baris yang salah
+	quantity int64 // jumlah gabungan, dihitung dengan int64 agar tidak overflow
+}
+
+// mergeCheckoutLines digunakan untuk memvalidasi setiap baris pesanan lalu menggabungkan baris
+// dengan produk dan varian yang sama dengan menjumlahkan kuantitasnya
+func mergeCheckoutLines(products []model.ProductQuantity) ([]checkoutLine, []model.FieldError) {
+	lines := []checkoutLine{}
+	fieldErrors := []model.FieldError{}
+	position := make(map[model.ProductQuantity]int)
+
+	for i, p := range products {
+		field := fmt.Sprintf("products[%d]", i)
+		valid := true
+
+		if p.ID == "" {
+			fieldErrors = append(fieldErrors, model.NewFieldError(field+".id", "product_id_required", "ID produk wajib diisi"))
+			valid = false
+		}
+
+		if p.Quantity <= 0 {
+			fieldErrors = append(fieldErrors, model.NewFieldError(field+".quantity", "quantity_positive", "Jumlah produk harus lebih dari 0"))
+			valid = false
+		}
+
+		if !valid {
+			continue
+		}
+
+		// gabungkan dengan baris sebelumnya yang memesan produk dan varian yang sama
+		key := model.ProductQuantity{ID: p.ID, VariantID: p.VariantID}
+		if j, ok := position[key]; ok {
+			lines[j].quantity += int64(p.Quantity)
+			continue
+		}
+
+		position[key] = len(lines)
+		lines = append(lines, checkoutLine{ProductQuantity: p, index: i, quantity: int64(p.Quantity)})
+	}
+
+	return lines, fieldErrors
+}
+
+// validateCheckoutLines digunakan untuk memastikan produk dan varian setiap baris ada, serta jumlah setiap produk
+// (seluruh variannya) tidak melebihi batas per pesanan milik produk atau maxQuantity jika produk tidak memiliki batas
+func validateCheckoutLines(lines []checkoutLine, productByID map[string]model.Product, variantByID map[string]model.ProductVariant, hasVariants map[string]bool, maxQuantity int) []model.FieldError {
+	fieldErrors := []model.FieldError{}
+	totals := make(map[string]int64)
+	firstLine := make(map[string]int)
+	order := []string{}
+
+	for _, line := range lines {
+		field := fmt.Sprintf("products[%d]", line.index)
+
+		p, ok := productByID[line.ID]
+		if !ok {
+			fieldErrors = append(fieldErrors, model.NewFieldError(field+".id", "product_not_found", "Produk tidak ditemukan"))
+			continue
+		}
+
+		if line.VariantID == "" {
+			// produk dengan varian wajib dipesan melalui variannya
+			if hasVariants[p.ID] {
+				fieldErrors = append(fieldErrors, model.NewFieldError(field+".variantId", "variant_required", "Varian produk wajib dipilih"))
+				continue
+			}
+		} else if v, ok := variantByID[line.VariantID]; !ok || v.ProductID != p.ID {
+			// varian harus ada dan milik produk yang dipesan
+			fieldErrors = append(fieldErrors, model.NewFieldError(field+".variantId", "variant_not_found", "Varian produk tidak ditemukan"))
+			continue
+		}
+
+		if _, ok := totals[p.ID]; !ok {
+			firstLine[p.ID] = line.index
+			order = append(order, p.ID)
+		}
+		totals[p.ID] += line.quantity
+	}
 
+	// batas jumlah berlaku per produk
+	for _, id := range order {
+		if limit := quantityLimit(productByID[id], maxQuantity); totals[id] > limit {
+			fieldErrors = append(fieldErrors, maxQuantityError(fmt.Sprintf("products[%d].quantity", firstLine[id]), limit))
+		}
+	}
+
+	return fieldErrors
+}
+
+// quantityLimit digunakan untuk mengambil batas jumlah produk per pesanan, yaitu batas milik produk
+// atau maxQuantity jika produk tidak memiliki batas
+func quantityLimit(p model.Product, maxQuantity int) int64 {
+	limit := int64(maxQuantity)
+	if p.MaxOrderQuantity != nil {
+		limit = int64(*p.MaxOrderQuantity)
+	}
+
+	// jumlah disimpan sebagai INT, tanpa batas berarti batas INT
+	if limit <= 0 || limit > math.MaxInt32 {
+		limit = math.MaxInt32
+	}
+
+	return limit
+}
+
+// maxQuantityError digunakan untuk membuat kesalahan field ketika jumlah produk melebihi batas
+func maxQuantityError(field string, limit int64) model.FieldError {
+	return model.FieldError{
+		Field:   field,
+		Code:    "max_quantity",
+		Message: fmt.Sprintf("Jumlah produk melebihi batas %d per pesanan", limit),
+		Params:  map[string]any{"max": limit},
+	}
+}
+
+// checkoutPromoError digunakan agar semua error kode promo saat checkout dikembalikan sebagai
+// kesalahan data pesanan (400) dengan kode error promo yang bersangkutan
+func checkoutPromoError(err error) error {
+	var promoErr *model.Error
+	if !errors.As(err, &promoErr) {
+		return err
+	}
+
+	for _, target := range []error{model.ErrPromoNotFound, model.ErrPromoInactive, model.ErrPromoMinOrder, model.ErrPromoNotApplicable, model.ErrPromoUsageExceeded} {
+		if errors.Is(promoErr, target) {
+			copied := *promoErr
+			copied.Kind = model.ErrorKindValidation
+			return &copied
+		}
+	}
+
+	return err
+}
+
+// generatePasscode digunakan untuk membuat passcode acak dari alphabet yang diberikan
+func generatePasscode(length int, alphabet string) (string, error) {
+	// ambil setiap karakter secara acak dengan crypto/rand tanpa bias modulo
+	size := big.NewInt(int64(len(alphabet)))
 	random := make([]byte, length)
 	for i := range random {
-		random[i] = charSet[randomGen.Intn(len(charSet))]
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		random[i] = alphabet[n.Int64()]
 	}
 
-	return string(random)
+	return string(random), nil
 }
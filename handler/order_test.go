@@ -0,0 +1,350 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fastcampus-backend-golang/online-shop/config"
+	"github.com/fastcampus-backend-golang/online-shop/mail"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// orderTestServer adalah router berisi endpoint pesanan yang memakai penyimpanan di memori
+type orderTestServer struct {
+	store    *repository.MemoryStore
+	products *repository.MemoryProductRepository
+	cfg      config.Config
+	router   *gin.Engine
+}
+
+// newOrderTestServer digunakan untuk menyiapkan router pesanan dengan dua produk:
+// "p1" (harga 10.000, stok 5) dan "p2" (harga 20.000, stok 1)
+func newOrderTestServer(t *testing.T) *orderTestServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := repository.NewMemoryStore()
+	products := repository.NewMemoryProductRepository(store)
+	orders := repository.NewMemoryOrderRepository(store)
+	carts := repository.NewMemoryCartRepository(store)
+
+	for _, p := range []model.Product{
+		{ID: "p1", Name: "Kaos", Price: 10000, Stock: int32Ptr(5)},
+		{ID: "p2", Name: "Topi", Price: 20000, Stock: int32Ptr(1)},
+	} {
+		if err := products.InsertProduct(p, nil); err != nil {
+			t.Fatalf("gagal menyimpan produk %s: %v", p.ID, err)
+		}
+	}
+
+	templates, err := mail.LoadTemplates("id")
+	if err != nil {
+		t.Fatalf("gagal memuat template email: %v", err)
+	}
+
+	cfg := config.Default()
+	notifier := NewOrderNotifier(repository.NewMemoryEmailRepository(store), templates, "id")
+	guard := NewPasscodeGuard(repository.NewMemoryPasscodeAttemptRepository(store), model.PasscodePolicy{
+		MaxOrderAttempts: cfg.Passcode.MaxOrderAttempts,
+		MaxIPAttempts:    cfg.Passcode.MaxIPAttempts,
+		BaseBackoff:      cfg.Passcode.Backoff,
+		Lockout:          cfg.Passcode.Lockout,
+	})
+
+	r := gin.New()
+	r.POST("/api/v1/checkout", CheckoutOrder(products, orders, carts, notifier, cfg.Order, cfg.Passcode))
+	r.POST("/api/v1/orders/:id/confirm", ConfirmOrder(orders, guard, notifier))
+	r.POST("/api/v1/orders/:id/cancel", CancelOrder(orders, guard, notifier))
+
+	return &orderTestServer{store: store, products: products, cfg: cfg, router: r}
+}
+
+// do digunakan untuk mengirim request JSON ke router
+func (s *orderTestServer) do(t *testing.T, method string, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("gagal membuat request body: %v", err)
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// checkout digunakan untuk membuat pesanan yang harus berhasil
+func (s *orderTestServer) checkout(t *testing.T, checkout model.Checkout) model.OrderWithDetail {
+	t.Helper()
+
+	w := s.do(t, http.MethodPost, "/api/v1/checkout", checkout)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("checkout: status %d, body %s", w.Code, w.Body.String())
+	}
+
+	var order model.OrderWithDetail
+	decode(t, w, &order)
+	return order
+}
+
+// stock digunakan untuk mengambil stok produk saat ini
+func (s *orderTestServer) stock(t *testing.T, id string) int32 {
+	t.Helper()
+
+	product, err := s.products.SelectProductByID(id)
+	if err != nil {
+		t.Fatalf("gagal mengambil produk %s: %v", id, err)
+	}
+	if product.Stock == nil {
+		return 0
+	}
+	return *product.Stock
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("gagal membaca response %s: %v", w.Body.String(), err)
+	}
+}
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func TestCheckoutMergesDuplicateLines(t *testing.T) {
+	s := newOrderTestServer(t)
+
+	order := s.checkout(t, model.Checkout{
+		Email:   "budi@example.com",
+		Address: "Jl. Merdeka 1",
+		Products: []model.ProductQuantity{
+			{ID: "p1", Quantity: 2},
+			{ID: "p1", Quantity: 1},
+		},
+	})
+
+	if len(order.Detail) != 1 {
+		t.Fatalf("jumlah detail = %d, seharusnya 1", len(order.Detail))
+	}
+	if order.Detail[0].Quantity != 3 || order.Detail[0].Total != 30000 {
+		t.Errorf("detail = %+v, seharusnya 3 x 10.000", order.Detail[0])
+	}
+	if want := 30000 + s.cfg.Order.ShippingFee; order.GrandTotal != want {
+		t.Errorf("grandTotal = %d, seharusnya %d", order.GrandTotal, want)
+	}
+	if order.Passcode == nil || *order.Passcode == "" {
+		t.Error("passcode tidak ditampilkan saat pesanan dibuat")
+	}
+	if got := s.stock(t, "p1"); got != 2 {
+		t.Errorf("stok p1 = %d, seharusnya 2", got)
+	}
+	if emails := s.store.Emails(); len(emails) != 1 || emails[0].To != "budi@example.com" {
+		t.Errorf("email pesanan dibuat tidak masuk antrean: %+v", emails)
+	}
+}
+
+func TestCheckoutInsufficientStock(t *testing.T) {
+	s := newOrderTestServer(t)
+
+	w := s.do(t, http.MethodPost, "/api/v1/checkout", model.Checkout{
+		Email:   "budi@example.com",
+		Address: "Jl. Merdeka 1",
+		Products: []model.ProductQuantity{
+			{ID: "p1", Quantity: 1},
+			{ID: "p2", Quantity: 2},
+		},
+	})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status %d, seharusnya 409: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Code     string                `json:"code"`
+		Products []model.StockShortage `json:"products"`
+	}
+	decode(t, w, &body)
+
+	if body.Code != "insufficient_stock" {
+		t.Errorf("code = %q, seharusnya insufficient_stock", body.Code)
+	}
+	if len(body.Products) != 1 || body.Products[0].ProductID != "p2" || body.Products[0].Requested != 2 || body.Products[0].Available != 1 {
+		t.Errorf("products = %+v, seharusnya hanya p2 (diminta 2, tersedia 1)", body.Products)
+	}
+
+	// pesanan gagal tidak boleh mengurangi stok produk lain
+	if got := s.stock(t, "p1"); got != 5 {
+		t.Errorf("stok p1 = %d, seharusnya tetap 5", got)
+	}
+	if emails := s.store.Emails(); len(emails) != 0 {
+		t.Errorf("email tidak boleh dikirim untuk checkout yang gagal: %+v", emails)
+	}
+}
+
+func TestCheckoutWithPromoCode(t *testing.T) {
+	s := newOrderTestServer(t)
+	s.store.AddPromoCode(model.PromoCode{ID: "promo-1", Code: "hemat10", Type: model.PromoTypePercentage, Value: 10})
+
+	order := s.checkout(t, model.Checkout{
+		Email:     "budi@example.com",
+		Address:   "Jl. Merdeka 1",
+		Products:  []model.ProductQuantity{{ID: "p1", Quantity: 2}},
+		PromoCode: "HEMAT10",
+	})
+
+	if order.DiscountTotal != 2000 {
+		t.Errorf("discountTotal = %d, seharusnya 2.000", order.DiscountTotal)
+	}
+	if len(order.Discounts) != 1 || order.Discounts[0].Code != "HEMAT10" {
+		t.Errorf("discounts = %+v, seharusnya satu potongan HEMAT10", order.Discounts)
+	}
+	if want := 20000 + s.cfg.Order.ShippingFee - 2000; order.GrandTotal != want {
+		t.Errorf("grandTotal = %d, seharusnya %d", order.GrandTotal, want)
+	}
+
+	// kode promo yang tidak ada ditolak sebagai kesalahan validasi
+	w := s.do(t, http.MethodPost, "/api/v1/checkout", model.Checkout{
+		Email:     "budi@example.com",
+		Address:   "Jl. Merdeka 1",
+		Products:  []model.ProductQuantity{{ID: "p1", Quantity: 1}},
+		PromoCode: "TIDAKADA",
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, seharusnya 400: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestConfirmOrder(t *testing.T) {
+	s := newOrderTestServer(t)
+	order := s.checkout(t, model.Checkout{
+		Email:    "budi@example.com",
+		Address:  "Jl. Merdeka 1",
+		Products: []model.ProductQuantity{{ID: "p1", Quantity: 1}},
+	})
+	path := "/api/v1/orders/" + order.ID + "/confirm"
+	confirm := model.Confirm{Amount: order.GrandTotal, Bank: "BCA", AccountNumber: "1234567890", Passcode: *order.Passcode}
+
+	// jumlah pembayaran harus sama dengan total pesanan
+	wrongAmount := confirm
+	wrongAmount.Amount = order.GrandTotal - 1
+	if w := s.do(t, http.MethodPost, path, wrongAmount); w.Code != http.StatusBadRequest {
+		t.Errorf("jumlah salah: status %d, seharusnya 400: %s", w.Code, w.Body.String())
+	}
+
+	w := s.do(t, http.MethodPost, path, confirm)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, seharusnya 200: %s", w.Code, w.Body.String())
+	}
+
+	var paid model.OrderWithDetail
+	decode(t, w, &paid)
+	if paid.Status != model.OrderStatusPaid || paid.PaidAt == nil {
+		t.Errorf("status = %s, paidAt = %v, seharusnya sudah dibayar", paid.Status, paid.PaidAt)
+	}
+	if paid.Passcode != nil {
+		t.Error("passcode tidak boleh ditampilkan setelah konfirmasi")
+	}
+	if n := len(paid.History); n != 2 || paid.History[n-1].ToStatus != model.OrderStatusPaid {
+		t.Errorf("riwayat status = %+v, seharusnya pending lalu paid", paid.History)
+	}
+
+	// pesanan yang sudah dibayar tidak dapat dikonfirmasi lagi
+	w = s.do(t, http.MethodPost, path, confirm)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("konfirmasi ulang: status %d, seharusnya 409: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	decode(t, w, &body)
+	if body.Code != "order_already_paid" {
+		t.Errorf("code = %q, seharusnya order_already_paid", body.Code)
+	}
+
+	// passcode salah ditolak sebelum status pesanan diperiksa
+	wrongPasscode := confirm
+	wrongPasscode.Passcode = "salah"
+	if w := s.do(t, http.MethodPost, path, wrongPasscode); w.Code != http.StatusUnauthorized {
+		t.Errorf("passcode salah: status %d, seharusnya 401: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	s := newOrderTestServer(t)
+	order := s.checkout(t, model.Checkout{
+		Email:    "budi@example.com",
+		Address:  "Jl. Merdeka 1",
+		Products: []model.ProductQuantity{{ID: "p1", Quantity: 3}},
+	})
+	if got := s.stock(t, "p1"); got != 2 {
+		t.Fatalf("stok p1 = %d, seharusnya 2 setelah checkout", got)
+	}
+
+	w := s.do(t, http.MethodPost, "/api/v1/orders/"+order.ID+"/cancel", model.Cancel{Passcode: *order.Passcode, Reason: "Salah ukuran"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, seharusnya 200: %s", w.Code, w.Body.String())
+	}
+
+	var cancelled model.OrderWithDetail
+	decode(t, w, &cancelled)
+	if cancelled.Status != model.OrderStatusCancelled || cancelled.CancelReason == nil || *cancelled.CancelReason != "Salah ukuran" {
+		t.Errorf("status = %s, alasan = %v, seharusnya dibatalkan dengan alasan", cancelled.Status, cancelled.CancelReason)
+	}
+
+	// stok dikembalikan saat pesanan dibatalkan
+	if got := s.stock(t, "p1"); got != 5 {
+		t.Errorf("stok p1 = %d, seharusnya kembali 5", got)
+	}
+
+	// pesanan yang dibatalkan tidak dapat dibatalkan lagi maupun dibayar
+	if w := s.do(t, http.MethodPost, "/api/v1/orders/"+order.ID+"/cancel", model.Cancel{Passcode: *order.Passcode, Reason: "Lagi"}); w.Code != http.StatusConflict {
+		t.Errorf("batal ulang: status %d, seharusnya 409: %s", w.Code, w.Body.String())
+	}
+	confirm := model.Confirm{Amount: order.GrandTotal, Bank: "BCA", AccountNumber: "1234567890", Passcode: *order.Passcode}
+	if w := s.do(t, http.MethodPost, "/api/v1/orders/"+order.ID+"/confirm", confirm); w.Code != http.StatusConflict {
+		t.Errorf("bayar setelah batal: status %d, seharusnya 409: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestOrderEmailsUseCheckoutLocale(t *testing.T) {
+	s := newOrderTestServer(t)
+
+	// bahasa dipilih saat checkout, bukan bahasa bawaan server
+	w := s.do(t, http.MethodPost, "/api/v1/checkout?lang=en", model.Checkout{
+		Email:    "budi@example.com",
+		Address:  "Jl. Merdeka 1",
+		Products: []model.ProductQuantity{{ID: "p1", Quantity: 1}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("checkout: status %d, body %s", w.Code, w.Body.String())
+	}
+
+	var order model.OrderWithDetail
+	decode(t, w, &order)
+
+	// konfirmasi tanpa parameter bahasa tetap memakai bahasa pesanan
+	confirm := model.Confirm{Amount: order.GrandTotal, Bank: "BCA", AccountNumber: "1234567890", Passcode: *order.Passcode}
+	if w := s.do(t, http.MethodPost, "/api/v1/orders/"+order.ID+"/confirm", confirm); w.Code != http.StatusOK {
+		t.Fatalf("konfirmasi: status %d, body %s", w.Code, w.Body.String())
+	}
+
+	emails := s.store.Emails()
+	if len(emails) != 2 {
+		t.Fatalf("jumlah email = %d, seharusnya 2", len(emails))
+	}
+	if want := "Order " + order.ID + " has been placed"; emails[0].Subject != want {
+		t.Errorf("subject = %q, seharusnya %q", emails[0].Subject, want)
+	}
+	if want := "Payment for order " + order.ID + " received"; emails[1].Subject != want {
+		t.Errorf("subject = %q, seharusnya %q", emails[1].Subject, want)
+	}
+}
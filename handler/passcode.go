@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasscodeGuard digunakan untuk mencocokkan passcode pesanan sekaligus membatasi percobaan yang gagal
+// per pesanan dan per IP
+type PasscodeGuard struct {
+	attempts repository.PasscodeAttemptRepository
+	policy   model.PasscodePolicy
+}
+
+// NewPasscodeGuard digunakan untuk membuat PasscodeGuard
+func NewPasscodeGuard(attempts repository.PasscodeAttemptRepository, policy model.PasscodePolicy) *PasscodeGuard {
+	return &PasscodeGuard{attempts: attempts, policy: policy}
+}
+
+// verify digunakan untuk mencocokkan passcode pesanan. Jika gagal, response sudah dikirim dan fungsi mengembalikan false.
+func (g *PasscodeGuard) verify(c *gin.Context, order model.Order, passcode string) bool {
+	now := time.Now()
+	orderKey := model.AttemptKey{Scope: model.AttemptScopeOrder, Key: order.ID}
+	ipKey := model.AttemptKey{Scope: model.AttemptScopeIP, Key: c.ClientIP()}
+
+	// pastikan passcode tidak kosong agar tidak terjadi panic
+	if order.Passcode == nil {
+		response.Error(c, model.ErrInternal)
+		return false
+	}
+
+	// catat percobaan sebagai gagal sebelum passcode dicocokkan agar percobaan bersamaan tidak melewati jeda,
+	// tolak jika pesanan atau IP sedang dikunci
+	reservation, err := g.attempts.ReservePasscodeAttempt([]model.AttemptKey{orderKey, ipKey}, g.policy, now)
+	if err != nil {
+		response.Error(c, err)
+		return false
+	}
+
+	if !reservation.LockedUntil.IsZero() {
+		tooManyAttempts(c, reservation.LockedUntil.Sub(now))
+		return false
+	}
+
+	// cocokkan passcode, kegagalan sudah tercatat
+	if err := bcrypt.CompareHashAndPassword([]byte(*order.Passcode), []byte(passcode)); err != nil {
+		response.Error(c, model.ErrInvalidPasscode)
+		return false
+	}
+
+	// passcode benar, mulai ulang penghitung pesanan dan batalkan catatan pada penghitung IP
+	if err := g.attempts.ReleasePasscodeAttempt(reservation); err != nil {
+		fmt.Printf("Gagal membatalkan percobaan passcode pesanan %s: %v\n", order.ID, err)
+	}
+
+	return true
+}
+
+// tooManyAttempts digunakan untuk mengirim response 429 beserta header Retry-After dalam detik
+func tooManyAttempts(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(max(seconds, 1)))
+	response.Error(c, model.ErrTooManyPasscodeAttempts)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/config"
+	"github.com/fastcampus-backend-golang/online-shop/i18n"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxResetTokenPerHour adalah batas token pemulihan per pesanan dalam satu jam
+const maxResetTokenPerHour = 3
+
+func RecoverPasscode(orders repository.OrderRepository, notifier *OrderNotifier, passcodeCfg config.PasscodeConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id order dari URL
+		id := c.Param("id")
+
+		// ambil email checkout dari request body
+		var recovery model.RecoverPasscode
+		if err := c.BindJSON(&recovery); err != nil {
+			response.BindError(c, model.ErrInvalidPasscodeRecovery, err)
+			return
+		}
+
+		// response selalu sama agar tidak dapat dipakai untuk menebak pesanan atau email
+		accepted := gin.H{"message": i18n.Localize(c, "message.passcode_recovery_accepted", nil, "Jika data sesuai, instruksi pemulihan passcode akan dikirim ke email pesanan")}
+
+		// ambil data order dari database
+		order, err := orders.SelectOrderByID(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.JSON(202, accepted)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// email harus sama dengan email saat checkout
+		if !strings.EqualFold(strings.TrimSpace(recovery.Email), order.Email) {
+			c.JSON(202, accepted)
+			return
+		}
+
+		// batasi jumlah token agar email pelanggan tidak dibanjiri
+		now := time.Now()
+		count, err := orders.CountPasscodeResetTokenSince(order.ID, now.Add(-time.Hour))
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		if count >= maxResetTokenPerHour {
+			c.JSON(202, accepted)
+			return
+		}
+
+		// buat token acak, hanya hash-nya yang disimpan di database
+		token, err := generateToken()
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		resetToken := model.PasscodeResetToken{
+			ID:        uuid.New().String(),
+			OrderID:   order.ID,
+			TokenHash: model.HashToken(token),
+			CreatedAt: now,
+			ExpiresAt: now.Add(passcodeCfg.ResetTTL),
+		}
+
+		if err := orders.InsertPasscodeResetToken(resetToken); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// masukkan email ke antrean dalam bahasa request, dikirim oleh worker (dengan percobaan ulang)
+		// agar tidak hilang saat server email gangguan atau server dihentikan
+		notifier.notifyPasscodeReset(order, i18n.Language(c), token, resetToken.ExpiresAt)
+
+		c.JSON(202, accepted)
+	}
+}
+
+func ResetPasscode(orders repository.OrderRepository, guard *PasscodeGuard, passcodeCfg config.PasscodeConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id order dari URL
+		id := c.Param("id")
+
+		// ambil token pemulihan dari request body
+		var reset model.ResetPasscode
+		if err := c.BindJSON(&reset); err != nil {
+			response.BindError(c, model.ErrInvalidPasscodeReset, err)
+			return
+		}
+
+		// buat passcode baru
+		passcode, err := generatePasscode(passcodeCfg.Length, passcodeCfg.Alphabet)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// hash passcode untuk disimpan di database
+		hashPasscode, err := bcrypt.GenerateFromPassword([]byte(passcode), 10)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tukarkan token dengan passcode baru
+		err = orders.RedeemPasscodeResetToken(id, model.HashToken(reset.Token), string(hashPasscode), time.Now())
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// passcode sudah diganti, buka kunci percobaan passcode pesanan
+		orderKey := model.AttemptKey{Scope: model.AttemptScopeOrder, Key: id}
+		if err := guard.attempts.ResetPasscodeFailures(orderKey); err != nil {
+			fmt.Printf("Gagal menghapus percobaan passcode %s %s: %v\n", orderKey.Scope, orderKey.Key, err)
+		}
+
+		// tampilkan passcode baru yang tidak dihash
+		c.JSON(200, gin.H{"id": id, "passcode": passcode})
+	}
+}
+
+// generateToken digunakan untuk membuat token acak sepanjang 32 byte (token pemulihan passcode dan token keranjang)
+func generateToken() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
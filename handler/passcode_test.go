@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testPasscode adalah passcode seluruh pesanan pada pengujian pembatasan percobaan passcode
+const testPasscode = "rahasia"
+
+// newPasscodeTestRouter digunakan untuk menyiapkan endpoint GET pesanan dengan n pesanan ("order-0" dan seterusnya)
+// yang memakai testPasscode
+func newPasscodeTestRouter(t *testing.T, policy model.PasscodePolicy, n int) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := repository.NewMemoryStore()
+	orders := repository.NewMemoryOrderRepository(store)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPasscode), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("gagal membuat hash passcode: %v", err)
+	}
+	hashStr := string(hash)
+
+	for i := 0; i < n; i++ {
+		order := model.Order{ID: fmt.Sprintf("order-%d", i), Email: "budi@example.com", Passcode: &hashStr, CreatedAt: time.Now()}
+		if err := orders.CreateOrder(order, nil, nil, nil, nil); err != nil {
+			t.Fatalf("gagal menyimpan pesanan: %v", err)
+		}
+	}
+
+	guard := NewPasscodeGuard(repository.NewMemoryPasscodeAttemptRepository(store), policy)
+	r := gin.New()
+	r.GET("/api/v1/orders/:id", GetOrder(orders, guard))
+	return r
+}
+
+// getOrder digunakan untuk mengambil pesanan dengan passcode dari IP tertentu
+func getOrder(r *gin.Engine, id string, passcode string, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/"+id+"?passcode="+passcode, nil)
+	req.RemoteAddr = ip + ":1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPasscodeOrderLockout(t *testing.T) {
+	r := newPasscodeTestRouter(t, model.PasscodePolicy{MaxOrderAttempts: 3, MaxIPAttempts: 100, Lockout: time.Minute}, 1)
+
+	for i := 0; i < 3; i++ {
+		if w := getOrder(r, "order-0", "salah", "192.0.2.1"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("percobaan ke-%d: status %d, seharusnya 401", i+1, w.Code)
+		}
+	}
+
+	// pesanan dikunci untuk semua IP, termasuk dengan passcode yang benar
+	for _, ip := range []string{"192.0.2.1", "198.51.100.7"} {
+		w := getOrder(r, "order-0", testPasscode, ip)
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("IP %s: status %d, seharusnya 429", ip, w.Code)
+		}
+
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		if err != nil || retryAfter < 59 || retryAfter > 60 {
+			t.Errorf("IP %s: Retry-After = %q, seharusnya sekitar 60 detik", ip, w.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestPasscodeBackoffReturnsRetryAfter(t *testing.T) {
+	r := newPasscodeTestRouter(t, model.PasscodePolicy{MaxOrderAttempts: 5, MaxIPAttempts: 100, BaseBackoff: 30 * time.Second, Lockout: time.Hour}, 1)
+
+	if w := getOrder(r, "order-0", "salah", "192.0.2.1"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("status %d, seharusnya 401", w.Code)
+	}
+
+	// percobaan berikutnya selama jeda ditolak
+	w := getOrder(r, "order-0", testPasscode, "192.0.2.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, seharusnya 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, seharusnya 30", got)
+	}
+}
+
+func TestPasscodeSuccessResetsOrderCounter(t *testing.T) {
+	r := newPasscodeTestRouter(t, model.PasscodePolicy{MaxOrderAttempts: 3, MaxIPAttempts: 3, Lockout: time.Minute}, 1)
+
+	// passcode yang benar memulai ulang penghitung pesanan dan tidak menambah penghitung IP
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 2; i++ {
+			if w := getOrder(r, "order-0", "salah", fmt.Sprintf("192.0.2.%d", round*2+i+1)); w.Code != http.StatusUnauthorized {
+				t.Fatalf("putaran %d: status %d, seharusnya 401", round, w.Code)
+			}
+		}
+
+		for i := 0; i < 5; i++ {
+			if w := getOrder(r, "order-0", testPasscode, "203.0.113.1"); w.Code != http.StatusOK {
+				t.Fatalf("putaran %d: status %d, seharusnya 200: %s", round, w.Code, w.Body.String())
+			}
+		}
+	}
+}
+
+func TestPasscodeIPLockout(t *testing.T) {
+	r := newPasscodeTestRouter(t, model.PasscodePolicy{MaxOrderAttempts: 100, MaxIPAttempts: 3, Lockout: time.Minute}, 4)
+
+	// kegagalan pada pesanan yang berbeda dihitung pada penghitung IP yang sama
+	for i := 0; i < 3; i++ {
+		if w := getOrder(r, fmt.Sprintf("order-%d", i), "salah", "192.0.2.1"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("percobaan ke-%d: status %d, seharusnya 401", i+1, w.Code)
+		}
+	}
+
+	if w := getOrder(r, "order-3", testPasscode, "192.0.2.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("IP yang dikunci: status %d, seharusnya 429", w.Code)
+	}
+
+	// IP lain tetap dapat mengakses pesanan
+	if w := getOrder(r, "order-3", testPasscode, "198.51.100.7"); w.Code != http.StatusOK {
+		t.Errorf("IP lain: status %d, seharusnya 200: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPasscodeConcurrentGuesses(t *testing.T) {
+	r := newPasscodeTestRouter(t, model.PasscodePolicy{MaxOrderAttempts: 3, MaxIPAttempts: 100, BaseBackoff: time.Minute, Lockout: time.Hour}, 1)
+
+	// tebakan bersamaan tidak boleh melewati jeda: hanya satu yang sempat dicocokkan
+	const n = 20
+	codes := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes <- getOrder(r, "order-0", fmt.Sprintf("salah-%d", i), fmt.Sprintf("192.0.2.%d", i+1)).Code
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	counts := make(map[int]int)
+	for code := range codes {
+		counts[code]++
+	}
+	if counts[http.StatusUnauthorized] != 1 || counts[http.StatusTooManyRequests] != n-1 {
+		t.Errorf("status = %v, seharusnya 1x 401 dan %dx 429", counts, n-1)
+	}
+}
@@ -3,40 +3,122 @@ package handler
 import (
 	"database/sql"
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-func ListProducts(db *sql.DB) gin.HandlerFunc {
+func ListProducts(products repository.ProductRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// ambil parameter filter dari query URL
+		var filter model.ProductFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			response.BindError(c, model.ErrInvalidProductSearch, err)
+			return
+		}
+
+		// atur nilai default halaman
+		if filter.Page == 0 {
+			filter.Page = 1
+		}
+		if filter.Limit == 0 {
+			filter.Limit = 20
+		}
+
+		// pastikan rentang harga valid
+		if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+			response.Error(c, model.ErrInvalidPriceRange)
+			return
+		}
+
 		// ambil data produk dari database
-		products, err := model.SelectProduct(db)
+		list, total, err := products.SelectProduct(filter)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+			response.Error(c, err)
 			return
 		}
 
+		// tentukan halaman selanjutnya jika masih ada data
+		meta := model.PageMeta{
+			Total: total,
+			Page:  filter.Page,
+			Limit: filter.Limit,
+		}
+		if filter.Page*filter.Limit < total {
+			nextPage := filter.Page + 1
+			meta.NextPage = &nextPage
+		}
+
 		// tampilkan data produk
-		c.JSON(200, products)
+		c.JSON(200, model.ProductList{Data: list, Meta: meta})
 	}
 }
 
-func GetProduct(db *sql.DB) gin.HandlerFunc {
+func SearchProducts(products repository.ProductRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil kata kunci dari query URL
+		keyword := strings.TrimSpace(c.Query("q"))
+		if keyword == "" {
+			response.Error(c, model.ErrSearchKeywordRequired)
+			return
+		}
+
+		// ambil batas jumlah hasil pencarian
+		limit := 20
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > 100 {
+				response.Error(c, model.ErrInvalidProductSearch)
+				return
+			}
+			limit = parsed
+		}
+
+		// cari data produk di database
+		results, err := products.SearchProducts(keyword, limit)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan hasil pencarian
+		c.JSON(200, results)
+	}
+}
+
+func GetProduct(products repository.ProductRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// ambil id produk dari URL
 		id := c.Param("id")
 
 		// ambil data produk dari database
-		product, err := model.SelectProductByID(db, id)
+		product, err := products.SelectProductByID(id)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				c.JSON(404, gin.H{"error": "Produk tidak ditemukan"})
+				response.Error(c, model.ErrProductNotFound)
 				return
 			}
 
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+			response.Error(c, err)
+			return
+		}
+
+		// ambil kategori produk dari database
+		product.CategoryIDs, err = products.SelectProductCategoryIDs(id)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ambil varian produk dari database
+		product.Variants, err = products.SelectVariantByProductID(id)
+		if err != nil {
+			response.Error(c, err)
 			return
 		}
 
@@ -45,21 +127,44 @@ func GetProduct(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
-func CreateProduct(db *sql.DB) gin.HandlerFunc {
+func CreateProduct(products repository.ProductRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// ambil data produk dari request body
 		var product model.Product
 		if err := c.BindJSON(&product); err != nil {
-			c.JSON(400, gin.H{"error": "Data produk tidak valid"})
+			response.BindError(c, model.ErrInvalidProduct, err)
 			return
 		}
 
 		// atur id dari UUID
 		product.ID = uuid.New().String()
 
-		// simpan data produk ke database
-		if err := model.InsertProduct(db, product); err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+		// stok default adalah 0 jika tidak diisi
+		if product.Stock == nil {
+			stock := int32(0)
+			product.Stock = &stock
+		}
+
+		// batas jumlah 0 berarti tanpa batas khusus produk
+		if product.MaxOrderQuantity != nil && *product.MaxOrderQuantity == 0 {
+			product.MaxOrderQuantity = nil
+		}
+
+		// susun audit log yang disimpan bersama data produk
+		audit, err := newAuditLog(c, auditActionCreate, "product", product.ID, nil, product)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data produk beserta kategorinya (jika diisi) ke database
+		if err := products.InsertProduct(product, audit); err != nil {
+			if errors.Is(err, model.ErrCategoryNotFound) {
+				response.Error(c, model.ErrInvalidProduct.WithFields(model.NewFieldError("categoryIds", "category_not_found", "Kategori tidak ditemukan")))
+				return
+			}
+
+			response.Error(c, err)
 			return
 		}
 
@@ -68,7 +173,7 @@ func CreateProduct(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
-func UpdateProduct(db *sql.DB) gin.HandlerFunc {
+func UpdateProduct(products repository.ProductRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// ambil id produk dari URL
 		id := c.Param("id")
@@ -76,15 +181,28 @@ func UpdateProduct(db *sql.DB) gin.HandlerFunc {
 		// ambil data produk dari request body
 		var productReq model.Product
 		if err := c.BindJSON(&productReq); err != nil {
-			c.JSON(400, gin.H{"error": "Data produk tidak valid"})
+			response.BindError(c, model.ErrInvalidProduct, err)
 			return
 		}
 
 		// ambil data produk dari database
-		product, err := model.SelectProductByID(db, id)
+		product, err := products.SelectProductByID(id)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				c.JSON(404, gin.H{"error": "Produk tidak ditemukan"})
+				response.Error(c, model.ErrProductNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data sebelum diubah untuk audit log
+		before := product
+		if productReq.CategoryIDs != nil {
+			before.CategoryIDs, err = products.SelectProductCategoryIDs(id)
+			if err != nil {
+				response.Error(c, err)
 				return
 			}
 		}
@@ -94,14 +212,47 @@ func UpdateProduct(db *sql.DB) gin.HandlerFunc {
 			product.Name = productReq.Name
 		}
 
+		// update deskripsi produk jika tidak kosong
+		if productReq.Description != "" {
+			product.Description = productReq.Description
+		}
+
 		// update harga produk jika tidak kosong
 		if productReq.Price != 0 {
 			product.Price = productReq.Price
 		}
 
-		// update data produk ke database
-		if err := model.UpdateProduct(db, product); err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+		// update stok produk jika diisi (0 tetap dianggap valid)
+		if productReq.Stock != nil {
+			product.Stock = productReq.Stock
+		}
+
+		// update batas jumlah per pesanan jika diisi (0 menghapus batas khusus produk)
+		if productReq.MaxOrderQuantity != nil {
+			product.MaxOrderQuantity = productReq.MaxOrderQuantity
+			if *product.MaxOrderQuantity == 0 {
+				product.MaxOrderQuantity = nil
+			}
+		}
+
+		// update kategori produk jika diisi (slice kosong menghapus semua kategori)
+		product.CategoryIDs = productReq.CategoryIDs
+
+		// susun audit log yang disimpan bersama perubahan produk
+		audit, err := newAuditLog(c, auditActionUpdate, "product", product.ID, before, product)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// update data produk beserta kategorinya ke database
+		if err := products.UpdateProduct(product, audit); err != nil {
+			if errors.Is(err, model.ErrCategoryNotFound) {
+				response.Error(c, model.ErrInvalidProduct.WithFields(model.NewFieldError("categoryIds", "category_not_found", "Kategori tidak ditemukan")))
+				return
+			}
+
+			response.Error(c, err)
 			return
 		}
 
@@ -110,14 +261,31 @@ func UpdateProduct(db *sql.DB) gin.HandlerFunc {
 	}
 }
 
-func DeleteProduct(db *sql.DB) gin.HandlerFunc {
+func DeleteProduct(products repository.ProductRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// ambil id produk dari URL
 		id := c.Param("id")
 
+		// ambil data produk sebelum dihapus untuk audit log
+		before, err := products.SelectProductByID(id)
+		found := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Error(c, err)
+			return
+		}
+
+		// susun audit log jika produk memang ada
+		var audit *model.AuditLog
+		if found {
+			if audit, err = newAuditLog(c, auditActionDelete, "product", id, before, nil); err != nil {
+				response.Error(c, err)
+				return
+			}
+		}
+
 		// hapus data produk dari database
-		if err := model.DeleteProduct(db, id); err != nil {
-			c.JSON(500, gin.H{"error": "Terjadi kesalahan pada server"})
+		if err := products.DeleteProduct(id, audit); err != nil {
+			response.Error(c, err)
 			return
 		}
 
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSearchProductsEscapesHighlight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	products := repository.NewMemoryProductRepository(repository.NewMemoryStore())
+	product := model.Product{ID: "p1", Name: "Kaos <script>alert(1)</script>", Description: `Bahan "katun" & nyaman`, Price: 10000}
+	if err := products.InsertProduct(product, nil); err != nil {
+		t.Fatalf("gagal menyimpan produk: %v", err)
+	}
+
+	r := gin.New()
+	r.GET("/api/v1/products/search", SearchProducts(products))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/search?q=kaos+katun", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, seharusnya 200: %s", w.Code, w.Body.String())
+	}
+
+	var results []model.ProductSearchResult
+	decode(t, w, &results)
+	if len(results) != 1 {
+		t.Fatalf("jumlah hasil = %d, seharusnya 1", len(results))
+	}
+
+	// hanya <mark> yang boleh menjadi tag HTML, nama dan deskripsi tetap teks biasa
+	want := "<mark>Kaos</mark> &lt;script&gt;alert(1)&lt;/script&gt; Bahan &#34;<mark>katun</mark>&#34; &amp; nyaman"
+	if results[0].Highlight != want {
+		t.Errorf("highlight = %q, seharusnya %q", results[0].Highlight, want)
+	}
+	if results[0].Name != product.Name {
+		t.Errorf("name = %q, seharusnya tidak di-escape", results[0].Name)
+	}
+}
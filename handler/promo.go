@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func ListPromoCodes(promos repository.PromoRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil data kode promo dari database
+		promos, err := promos.SelectPromoCode()
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data kode promo
+		c.JSON(200, promos)
+	}
+}
+
+func CreatePromoCode(promos repository.PromoRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil data kode promo dari request body
+		var promo model.PromoCode
+		if err := c.BindJSON(&promo); err != nil {
+			response.BindError(c, model.ErrInvalidPromo, err)
+			return
+		}
+
+		// atur id dari UUID dan seragamkan kode promo
+		promo.ID = uuid.New().String()
+		promo.Code = model.NormalizePromoCode(promo.Code)
+
+		// kode promo aktif jika tidak diisi
+		if promo.IsActive == nil {
+			isActive := true
+			promo.IsActive = &isActive
+		}
+
+		// validasi aturan kode promo
+		if err := validatePromoCode(promo); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// susun audit log yang disimpan bersama data kode promo
+		audit, err := newAuditLog(c, auditActionCreate, "promo_code", promo.ID, nil, promo)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data kode promo ke database
+		if err := promos.InsertPromoCode(promo, audit); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// ambil data kode promo yang disimpan
+		promo, err = promos.SelectPromoCodeByID(promo.ID)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data kode promo yang disimpan
+		c.JSON(201, promo)
+	}
+}
+
+func UpdatePromoCode(promos repository.PromoRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id kode promo dari URL
+		id := c.Param("id")
+
+		// ambil data kode promo dari request body
+		var promoReq model.PromoCode
+		if err := c.BindJSON(&promoReq); err != nil {
+			response.BindError(c, model.ErrInvalidPromo, err)
+			return
+		}
+
+		// ambil data kode promo dari database
+		promo, err := promos.SelectPromoCodeByID(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrPromoNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data sebelum diubah untuk audit log
+		before := promo
+
+		// update kode dan jenis promo jika tidak kosong
+		if promoReq.Code != "" {
+			promo.Code = model.NormalizePromoCode(promoReq.Code)
+		}
+		if promoReq.Type != "" {
+			promo.Type = promoReq.Type
+		}
+
+		// update nilai potongan dan minimum belanja jika tidak kosong
+		if promoReq.Value != 0 {
+			promo.Value = promoReq.Value
+		}
+		if promoReq.MinOrderAmount != 0 {
+			promo.MinOrderAmount = promoReq.MinOrderAmount
+		}
+
+		// update nilai opsional jika diisi
+		if promoReq.MaxDiscount != nil {
+			promo.MaxDiscount = promoReq.MaxDiscount
+		}
+		if promoReq.StartsAt != nil {
+			promo.StartsAt = promoReq.StartsAt
+		}
+		if promoReq.EndsAt != nil {
+			promo.EndsAt = promoReq.EndsAt
+		}
+		if promoReq.UsageLimit != nil {
+			promo.UsageLimit = promoReq.UsageLimit
+		}
+		if promoReq.UsageLimitPerEmail != nil {
+			promo.UsageLimitPerEmail = promoReq.UsageLimitPerEmail
+		}
+		if promoReq.IsActive != nil {
+			promo.IsActive = promoReq.IsActive
+		}
+
+		// update cakupan promo jika diisi (slice kosong berarti berlaku untuk semua produk)
+		if promoReq.ProductIDs != nil {
+			promo.ProductIDs = promoReq.ProductIDs
+		}
+		if promoReq.CategoryIDs != nil {
+			promo.CategoryIDs = promoReq.CategoryIDs
+		}
+
+		// validasi aturan kode promo
+		if err := validatePromoCode(promo); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// susun audit log yang disimpan bersama perubahan kode promo
+		audit, err := newAuditLog(c, auditActionUpdate, "promo_code", promo.ID, before, promo)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// update data kode promo ke database
+		if err := promos.UpdatePromoCode(promo, audit); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data kode promo yang diupdate
+		c.JSON(200, promo)
+	}
+}
+
+func DeletePromoCode(promos repository.PromoRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id kode promo dari URL
+		id := c.Param("id")
+
+		// ambil data kode promo sebelum dinonaktifkan untuk audit log
+		before, err := promos.SelectPromoCodeByID(id)
+		found := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Error(c, err)
+			return
+		}
+
+		// susun audit log jika kode promo memang ada
+		var audit *model.AuditLog
+		if found {
+			after := before
+			isActive := false
+			after.IsActive = &isActive
+			if audit, err = newAuditLog(c, auditActionDelete, "promo_code", id, before, after); err != nil {
+				response.Error(c, err)
+				return
+			}
+		}
+
+		// nonaktifkan kode promo agar riwayat pemakaian pada pesanan tetap ada
+		if err := promos.DeactivatePromoCode(id, audit); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data kode promo yang dihapus
+		c.JSON(204, nil)
+	}
+}
+
+// validatePromoCode digunakan untuk memvalidasi aturan kode promo sesuai jenisnya
+func validatePromoCode(promo model.PromoCode) error {
+	if promo.Code == "" {
+		return model.ErrInvalidPromo.WithFields(model.NewFieldError("code", "promo_code_required", "Kode promo wajib diisi"))
+	}
+
+	switch promo.Type {
+	case model.PromoTypePercentage:
+		if promo.Value < 1 || promo.Value > 100 {
+			return model.ErrInvalidPromo.WithFields(model.NewFieldError("value", "percentage_range", "Nilai persentase harus antara 1 dan 100"))
+		}
+	case model.PromoTypeFixed:
+		if promo.Value < 1 {
+			return model.ErrInvalidPromo.WithFields(model.NewFieldError("value", "discount_positive", "Nilai potongan harus lebih dari 0"))
+		}
+	case model.PromoTypeFreeShipping:
+	default:
+		return model.ErrInvalidPromo.WithFields(model.NewFieldError("type", "promo_type_invalid", "Jenis kode promo tidak valid"))
+	}
+
+	if promo.StartsAt != nil && promo.EndsAt != nil && promo.EndsAt.Before(*promo.StartsAt) {
+		return model.ErrInvalidPromo.WithFields(model.NewFieldError("endsAt", "promo_period_invalid", "Masa berlaku kode promo tidak valid"))
+	}
+
+	return nil
+}
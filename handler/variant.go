@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func CreateVariant(products repository.ProductRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id produk dari URL
+		productID := c.Param("id")
+
+		// ambil data varian dari request body
+		var variant model.ProductVariant
+		if err := c.BindJSON(&variant); err != nil {
+			response.BindError(c, model.ErrInvalidVariant, err)
+			return
+		}
+
+		// pastikan kode SKU diisi
+		if variant.SKU == "" {
+			response.Error(c, model.ErrSKURequired)
+			return
+		}
+
+		// pastikan produk ada
+		if _, err := products.SelectProductByID(productID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrProductNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// atur id dari UUID dan produk induknya
+		variant.ID = uuid.New().String()
+		variant.ProductID = productID
+
+		// stok default adalah 0 jika tidak diisi
+		if variant.Stock == nil {
+			stock := int32(0)
+			variant.Stock = &stock
+		}
+
+		// susun audit log yang disimpan bersama data varian
+		audit, err := newAuditLog(c, auditActionCreate, "product_variant", variant.ID, nil, variant)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data varian ke database
+		if err := products.InsertVariant(variant, audit); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data varian yang disimpan
+		c.JSON(201, variant)
+	}
+}
+
+func UpdateVariant(products repository.ProductRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id produk dan id varian dari URL
+		productID := c.Param("id")
+		id := c.Param("variantId")
+
+		// ambil data varian dari request body
+		var variantReq model.ProductVariant
+		if err := c.BindJSON(&variantReq); err != nil {
+			response.BindError(c, model.ErrInvalidVariant, err)
+			return
+		}
+
+		// ambil data varian dari database
+		variant, err := products.SelectVariantByID(productID, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrVariantNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// simpan data sebelum diubah untuk audit log
+		before := variant
+
+		// update kode SKU jika tidak kosong
+		if variantReq.SKU != "" {
+			variant.SKU = variantReq.SKU
+		}
+
+		// update opsi varian jika diisi
+		if variantReq.Options != nil {
+			variant.Options = variantReq.Options
+		}
+
+		// update harga varian jika diisi
+		if variantReq.Price != nil {
+			variant.Price = variantReq.Price
+		}
+
+		// update stok varian jika diisi (0 tetap dianggap valid)
+		if variantReq.Stock != nil {
+			variant.Stock = variantReq.Stock
+		}
+
+		// susun audit log yang disimpan bersama perubahan varian
+		audit, err := newAuditLog(c, auditActionUpdate, "product_variant", variant.ID, before, variant)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// update data varian ke database
+		if err := products.UpdateVariant(variant, audit); err != nil {
+			// varian dihapus setelah dibaca
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(c, model.ErrVariantNotFound)
+				return
+			}
+
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data varian yang diupdate
+		c.JSON(200, variant)
+	}
+}
+
+func DeleteVariant(products repository.ProductRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil id produk dan id varian dari URL
+		productID := c.Param("id")
+		id := c.Param("variantId")
+
+		// ambil data varian sebelum dihapus untuk audit log
+		before, err := products.SelectVariantByID(productID, id)
+		found := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Error(c, err)
+			return
+		}
+
+		// susun audit log jika varian memang ada
+		var audit *model.AuditLog
+		if found {
+			if audit, err = newAuditLog(c, auditActionDelete, "product_variant", id, before, nil); err != nil {
+				response.Error(c, err)
+				return
+			}
+		}
+
+		// hapus data varian dari database
+		if err := products.DeleteVariant(productID, id, audit); err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		// tampilkan data varian yang dihapus
+		c.JSON(204, nil)
+	}
+}
@@ -0,0 +1,163 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultLanguage adalah bahasa bawaan API, pesan yang ditulis di kode memakai bahasa ini
+const DefaultLanguage = "id"
+
+// QueryParam adalah parameter query untuk memilih bahasa, lebih diutamakan dari header Accept-Language
+const QueryParam = "lang"
+
+// languageKey adalah kunci gin.Context untuk menyimpan bahasa request
+const languageKey = "lang"
+
+//go:embed locales
+var localeFS embed.FS
+
+// catalogs adalah katalog pesan per bahasa, dibaca dari locales/<bahasa>.json
+var catalogs = mustLoadCatalogs()
+
+// mustLoadCatalogs digunakan untuk membaca seluruh katalog pesan yang tertanam di binary
+func mustLoadCatalogs() map[string]map[string]string {
+	files, err := fs.Glob(localeFS, "locales/*.json")
+	if err != nil {
+		panic(err)
+	}
+
+	result := make(map[string]map[string]string)
+	for _, file := range files {
+		data, err := localeFS.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("katalog pesan %s tidak valid: %v", file, err))
+		}
+		result[strings.TrimSuffix(path.Base(file), ".json")] = messages
+	}
+
+	if _, ok := result[DefaultLanguage]; !ok {
+		panic(fmt.Sprintf("katalog pesan untuk bahasa %q tidak ditemukan", DefaultLanguage))
+	}
+
+	return result
+}
+
+// Languages digunakan untuk mengambil daftar bahasa yang tersedia
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+// Has digunakan untuk memeriksa apakah pesan dengan kunci tertentu tersedia di bahasa bawaan
+func Has(key string) bool {
+	_, ok := catalogs[DefaultLanguage][key]
+	return ok
+}
+
+// Negotiate digunakan untuk memilih bahasa dari parameter lang, lalu header Accept-Language
+// (sesuai urutan bobot q), dan bahasa bawaan jika tidak ada yang tersedia
+func Negotiate(lang string, acceptLanguage string) string {
+	if language, ok := supported(lang); ok {
+		return language
+	}
+
+	best, bestWeight := DefaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, weight := parseLanguageRange(part)
+		if weight <= bestWeight {
+			continue
+		}
+
+		if language, ok := supported(tag); ok {
+			best, bestWeight = language, weight
+		}
+	}
+
+	return best
+}
+
+// parseLanguageRange digunakan untuk membaca satu bagian header Accept-Language, misalnya "en-US;q=0.8"
+func parseLanguageRange(part string) (string, float64) {
+	tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+	weight := 1.0
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || name != "q" {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(value, 64)
+		if err != nil || q < 0 || q > 1 {
+			return "", 0
+		}
+		weight = q
+	}
+
+	return strings.TrimSpace(tag), weight
+}
+
+// supported digunakan untuk mencocokkan tag bahasa (misalnya en-US) dengan katalog yang tersedia
+func supported(tag string) (string, bool) {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	if primary == "*" {
+		return DefaultLanguage, true
+	}
+
+	_, ok := catalogs[primary]
+	return primary, ok
+}
+
+// Language digunakan untuk mengambil bahasa request, dipilih sekali lalu disimpan di context
+func Language(c *gin.Context) string {
+	if language := c.GetString(languageKey); language != "" {
+		return language
+	}
+
+	language := Negotiate(c.Query(QueryParam), c.GetHeader("Accept-Language"))
+	c.Set(languageKey, language)
+
+	return language
+}
+
+// Text digunakan untuk mengambil pesan dalam bahasa tertentu dengan mengganti {nama} dengan nilai params.
+// Jika pesan tidak ada, dipakai pesan bahasa bawaan, lalu fallback.
+func Text(language string, key string, params map[string]any, fallback string) string {
+	message, ok := catalogs[language][key]
+	if !ok {
+		message, ok = catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		message = fallback
+	}
+
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", fmt.Sprint(value))
+	}
+
+	return message
+}
+
+// Localize digunakan untuk mengambil pesan dalam bahasa request
+func Localize(c *gin.Context, key string, params map[string]any, fallback string) string {
+	return Text(Language(c), key, params, fallback)
+}
@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileSender adalah Sender yang menulis email ke direktori sebagai file .eml, digunakan saat pengembangan
+type FileSender struct {
+	Dir  string
+	From string
+}
+
+// NewFileSender digunakan untuk membuat FileSender
+func NewFileSender(dir, from string) *FileSender {
+	return &FileSender{Dir: dir, From: from}
+}
+
+func (s *FileSender) Send(ctx context.Context, message Message) error {
+	body, err := buildMessage(s.From, message)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	// nama file diawali waktu agar mudah diurutkan, ditambah akhiran acak agar tidak bertabrakan
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	name := time.Now().Format("20060102-150405.000000000") + "-" + hex.EncodeToString(random) + ".eml"
+
+	return os.WriteFile(filepath.Join(s.Dir, name), body, 0o644)
+}
@@ -0,0 +1,28 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message adalah email yang akan dikirim
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string // opsional, jika kosong hanya versi teks yang dikirim
+}
+
+// Sender adalah kontrak pengiriman email sehingga penyedia email dapat diganti
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// LogSender adalah Sender yang hanya mencetak email ke stdout, hanya untuk pengembangan (MAIL_LOG_ONLY)
+// karena isi email (misalnya token pemulihan passcode) ikut tercetak ke log
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, message Message) error {
+	fmt.Printf("Email ke %s: %s\n%s\n", message.To, message.Subject, message.Text)
+	return nil
+}
@@ -0,0 +1,153 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPSender adalah Sender yang mengirim email melalui server SMTP.
+// Koneksi wajib dienkripsi dengan STARTTLS, kecuali Insecure diisi untuk server SMTP lokal tanpa TLS.
+type SMTPSender struct {
+	Host     string
+	Port     int
+	Username string // kosong berarti tanpa autentikasi
+	Password string
+	From     string
+	Insecure bool // jika true, email tetap dikirim tanpa TLS bila server tidak mendukung STARTTLS (hanya untuk pengembangan)
+}
+
+// NewSMTPSender digunakan untuk membuat SMTPSender
+func NewSMTPSender(host string, port int, username, password, from string, insecure bool) *SMTPSender {
+	return &SMTPSender{Host: host, Port: port, Username: username, Password: password, From: from, Insecure: insecure}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, message Message) error {
+	// pastikan tidak ada header injection melalui alamat atau subjek
+	for _, value := range []string{s.From, message.To, message.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header email tidak valid: %q", value)
+		}
+	}
+
+	body, err := buildMessage(s.From, message)
+	if err != nil {
+		return err
+	}
+
+	// buka koneksi dengan batas waktu dari context
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.Host, strconv.Itoa(s.Port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	// gunakan STARTTLS, tolak server tanpa TLS kecuali diizinkan
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	} else if !s.Insecure {
+		return fmt.Errorf("server SMTP %s tidak mendukung STARTTLS", s.Host)
+	}
+
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+
+	// kirim email
+	if err := client.Mail(s.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(body); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// buildMessage digunakan untuk menyusun email MIME, multipart/alternative jika ada versi HTML
+func buildMessage(from string, message Message) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if message.HTML == "" {
+		if err := writePart(&buf, "text/plain", message.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	// buat boundary acak untuk multipart
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	boundary := hex.EncodeToString(random)
+
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", boundary)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain", message.Text},
+		{"text/html", message.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		if err := writePart(&buf, part.contentType, part.content); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// writePart digunakan untuk menulis header dan isi satu bagian email dengan encoding quoted-printable
+func writePart(buf *bytes.Buffer, contentType, content string) error {
+	fmt.Fprintf(buf, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	writer := quotedprintable.NewWriter(buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
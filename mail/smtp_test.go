@@ -0,0 +1,237 @@
+package mail
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	netmail "net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession adalah data yang diterima server SMTP palsu dalam satu koneksi
+type smtpSession struct {
+	from string
+	rcpt []string
+	data []byte
+}
+
+// startFakeSMTP digunakan untuk menjalankan server SMTP palsu tanpa TLS dan autentikasi.
+// Sender yang dikembalikan mengizinkan koneksi tanpa TLS. Setiap koneksi yang selesai dikirim ke channel sessions.
+func startFakeSMTP(t *testing.T) (*SMTPSender, <-chan smtpSession) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("gagal membuka listener: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeSMTP(conn, sessions)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return NewSMTPSender("127.0.0.1", addr.Port, "", "", "toko@example.com", true), sessions
+}
+
+// serveFakeSMTP digunakan untuk melayani satu koneksi SMTP dengan perintah minimal
+func serveFakeSMTP(conn net.Conn, sessions chan<- smtpSession) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	session := smtpSession{}
+	tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			tp.PrintfLine("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			session.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			tp.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			session.rcpt = append(session.rcpt, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			tp.PrintfLine("250 OK")
+		case command == "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			session.data, err = tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			tp.PrintfLine("250 OK")
+		case command == "QUIT":
+			tp.PrintfLine("221 Bye")
+			sessions <- session
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// receive digunakan untuk menunggu satu sesi dari server SMTP palsu
+func receive(t *testing.T, sessions <-chan smtpSession) smtpSession {
+	t.Helper()
+
+	select {
+	case session := <-sessions:
+		return session
+	case <-time.After(5 * time.Second):
+		t.Fatal("server SMTP tidak menerima email")
+		return smtpSession{}
+	}
+}
+
+func TestSMTPSenderMultipart(t *testing.T) {
+	sender, sessions := startFakeSMTP(t)
+
+	message := Message{
+		To:      "budi@example.com",
+		Subject: "Pesanan dibayar — terima kasih",
+		Text:    "Halo Budi,\nPembayaran pesanan Anda sudah diterima.\n.\nBaris titik harus tetap utuh.",
+		HTML:    "<p>Halo Budi,</p><p>Pembayaran pesanan Anda sudah diterima.</p>",
+	}
+	if err := sender.Send(context.Background(), message); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	session := receive(t, sessions)
+	if session.from != "toko@example.com" {
+		t.Errorf("MAIL FROM = %q, seharusnya toko@example.com", session.from)
+	}
+	if len(session.rcpt) != 1 || session.rcpt[0] != "budi@example.com" {
+		t.Errorf("RCPT TO = %v, seharusnya [budi@example.com]", session.rcpt)
+	}
+
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(session.data)))
+	if err != nil {
+		t.Fatalf("email tidak dapat dibaca: %v", err)
+	}
+
+	if got := parsed.Header.Get("From"); got != "toko@example.com" {
+		t.Errorf("From = %q", got)
+	}
+	if got := parsed.Header.Get("To"); got != "budi@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if got := parsed.Header.Get("MIME-Version"); got != "1.0" {
+		t.Errorf("MIME-Version = %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != message.Subject {
+		t.Errorf("Subject = %q (err %v), seharusnya %q", subject, err, message.Subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (err %v), seharusnya multipart/alternative", parsed.Header.Get("Content-Type"), err)
+	}
+
+	// bagian teks harus lebih dulu dari HTML, isinya di-decode dari quoted-printable oleh multipart.Reader
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, want := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	} {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("bagian %s tidak ditemukan: %v", want.contentType, err)
+		}
+		if got := part.Header.Get("Content-Type"); got != want.contentType {
+			t.Errorf("Content-Type bagian = %q, seharusnya %q", got, want.contentType)
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("gagal membaca bagian %s: %v", want.contentType, err)
+		}
+		if got := strings.TrimRight(string(content), "\r\n"); got != want.content {
+			t.Errorf("isi %s = %q, seharusnya %q", want.contentType, got, want.content)
+		}
+	}
+
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("seharusnya hanya ada dua bagian, err = %v", err)
+	}
+}
+
+func TestSMTPSenderTextOnly(t *testing.T) {
+	sender, sessions := startFakeSMTP(t)
+
+	if err := sender.Send(context.Background(), Message{To: "budi@example.com", Subject: "Halo", Text: "Hanya teks"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(receive(t, sessions).data)))
+	if err != nil {
+		t.Fatalf("email tidak dapat dibaca: %v", err)
+	}
+
+	if got := parsed.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q, seharusnya text/plain; charset=utf-8", got)
+	}
+	if got := parsed.Header.Get("Content-Transfer-Encoding"); got != "quoted-printable" {
+		t.Errorf("Content-Transfer-Encoding = %q, seharusnya quoted-printable", got)
+	}
+}
+
+func TestSMTPSenderRejectsHeaderInjection(t *testing.T) {
+	sender, sessions := startFakeSMTP(t)
+
+	for name, message := range map[string]Message{
+		"to":      {To: "budi@example.com\r\nBcc: korban@example.com", Subject: "Halo", Text: "isi"},
+		"subject": {To: "budi@example.com", Subject: "Halo\nBcc: korban@example.com", Text: "isi"},
+	} {
+		if err := sender.Send(context.Background(), message); err == nil {
+			t.Errorf("%s: header dengan CR/LF seharusnya ditolak", name)
+		}
+	}
+
+	from := *sender
+	from.From = "toko@example.com\rBcc: korban@example.com"
+	if err := from.Send(context.Background(), Message{To: "budi@example.com", Subject: "Halo", Text: "isi"}); err == nil {
+		t.Error("from: header dengan CR/LF seharusnya ditolak")
+	}
+
+	// email yang ditolak tidak boleh sampai ke server
+	select {
+	case session := <-sessions:
+		t.Errorf("server menerima email yang seharusnya ditolak: %+v", session)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSMTPSenderRequiresTLS(t *testing.T) {
+	sender, sessions := startFakeSMTP(t)
+
+	// server tanpa STARTTLS ditolak jika koneksi tanpa TLS tidak diizinkan
+	secure := *sender
+	secure.Insecure = false
+	err := secure.Send(context.Background(), Message{To: "budi@example.com", Subject: "Halo", Text: "isi"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("err = %v, seharusnya ditolak karena server tidak mendukung STARTTLS", err)
+	}
+
+	select {
+	case session := <-sessions:
+		t.Errorf("server menerima email tanpa TLS: %+v", session)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
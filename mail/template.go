@@ -0,0 +1,161 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Templates adalah kumpulan template email (teks dan HTML) per bahasa.
+// Setiap template berada di templates/<bahasa>/<nama>.txt.tmpl dan <nama>.html.tmpl,
+// dengan subjek email didefinisikan di blok "subject" pada template teks.
+type Templates struct {
+	defaultLocale string
+	text          map[string]*texttemplate.Template // kunci: <bahasa>/<nama>
+	html          map[string]*htmltemplate.Template
+}
+
+// LoadTemplates digunakan untuk membaca seluruh template email yang tertanam di binary
+func LoadTemplates(defaultLocale string) (*Templates, error) {
+	t := &Templates{
+		defaultLocale: defaultLocale,
+		text:          map[string]*texttemplate.Template{},
+		html:          map[string]*htmltemplate.Template{},
+	}
+
+	err := fs.WalkDir(templateFS, "templates", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		locale := path.Base(path.Dir(file))
+		name := path.Base(file)
+		funcs := templateFuncs(locale)
+
+		switch {
+		case strings.HasSuffix(name, ".txt.tmpl"):
+			key := locale + "/" + strings.TrimSuffix(name, ".txt.tmpl")
+			tmpl, err := texttemplate.New(name).Funcs(funcs).ParseFS(templateFS, file)
+			if err != nil {
+				return err
+			}
+			t.text[key] = tmpl
+		case strings.HasSuffix(name, ".html.tmpl"):
+			key := locale + "/" + strings.TrimSuffix(name, ".html.tmpl")
+			tmpl, err := htmltemplate.New(name).Funcs(funcs).ParseFS(templateFS, file)
+			if err != nil {
+				return err
+			}
+			t.html[key] = tmpl
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.HasLocale(defaultLocale) {
+		return nil, fmt.Errorf("template email untuk bahasa %q tidak ditemukan", defaultLocale)
+	}
+
+	return t, nil
+}
+
+// HasLocale digunakan untuk memeriksa apakah tersedia template untuk bahasa tertentu
+func (t *Templates) HasLocale(locale string) bool {
+	for key := range t.text {
+		if strings.HasPrefix(key, locale+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Render digunakan untuk membuat email dari template. Jika template tidak tersedia untuk bahasa
+// yang diminta, template bahasa bawaan yang digunakan.
+func (t *Templates) Render(locale, name, to string, data any) (Message, error) {
+	if !t.HasLocale(locale) {
+		locale = t.defaultLocale
+	}
+
+	key := locale + "/" + name
+	text, ok := t.text[key]
+	if !ok {
+		return Message{}, fmt.Errorf("template email %q tidak ditemukan", key)
+	}
+
+	var subject, body bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+
+	message := Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+	}
+
+	// versi HTML bersifat opsional
+	if html, ok := t.html[key]; ok {
+		var buf bytes.Buffer
+		if err := html.Execute(&buf, data); err != nil {
+			return Message{}, err
+		}
+		message.HTML = buf.String()
+	}
+
+	return message, nil
+}
+
+// templateFuncs adalah fungsi bantu yang dapat dipakai di template sesuai bahasanya
+func templateFuncs(locale string) map[string]any {
+	return map[string]any{
+		"money": formatMoney,
+		"date": func(value time.Time) string {
+			if locale == "en" {
+				return value.Format("January 2, 2006 15:04 MST")
+			}
+			return fmt.Sprintf("%d %s %d %s", value.Day(), indonesianMonths[value.Month()-1], value.Year(), value.Format("15:04 MST"))
+		},
+	}
+}
+
+var indonesianMonths = []string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// formatMoney digunakan untuk menampilkan nominal rupiah dengan pemisah ribuan, contoh: Rp150.000
+func formatMoney(value int64) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	digits := strconv.FormatInt(value, 10)
+	var buf strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			buf.WriteByte('.')
+		}
+		buf.WriteRune(digit)
+	}
+
+	return sign + "Rp" + buf.String()
+}
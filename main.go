@@ -1,17 +1,49 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/fastcampus-backend-golang/online-shop/config"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/worker"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
 func main() {
+	// baca konfigurasi dari file, environment variable, dan flag
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Printf("Gagal membaca konfigurasi: %v\n", err)
+		os.Exit(1)
+	}
+
+	// subcommand operasional (migrate, admin) hanya membutuhkan DB_URI, server membutuhkan seluruh konfigurasi
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	validate := cfg.Validate
+	if command == "migrate" || command == "admin" {
+		validate = cfg.ValidateDatabase
+	}
+
+	if err := validate(); err != nil {
+		fmt.Printf("Konfigurasi tidak valid: %v\n", err)
+		os.Exit(1)
+	}
+
 	// buat koneksi database
-	db, err := sql.Open("pgx", os.Getenv("DB_URI"))
+	db, err := sql.Open("pgx", cfg.Database.URI)
 	if err != nil {
 		fmt.Printf("Gagal membuat koneksi ke database: %v\n", err)
 		os.Exit(1)
@@ -24,14 +56,59 @@ func main() {
 		os.Exit(1)
 	}
 
+	// jalankan subcommand migrate lalu keluar tanpa menjalankan server
+	if command == "migrate" {
+		if err = runMigrateCommand(db, args[1:]); err != nil {
+			fmt.Printf("Gagal menjalankan migrasi: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// jalankan subcommand admin lalu keluar tanpa menjalankan server
+	if command == "admin" {
+		if err = runAdminCommand(db, args[1:], os.Stdin); err != nil {
+			fmt.Printf("Gagal menjalankan perintah admin: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// lakukan migrasi tabel database
 	if err = migrate(db); err != nil {
 		fmt.Printf("Gagal melakukan migrasi database: %v\n", err)
 		os.Exit(1)
 	}
 
+	// tangkap sinyal SIGINT & SIGTERM untuk memulai shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// jalankan worker untuk menandai pesanan kedaluwarsa, mengirim antrean email, dan menghapus Idempotency-Key lama
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	workers.Add(3)
+	go func() {
+		defer workers.Done()
+		worker.ExpireOrders(workerCtx, db, cfg.Order.ExpiryInterval)
+	}()
+	go func() {
+		defer workers.Done()
+		worker.SendEmails(workerCtx, db, mailSender(cfg.Mail), model.EmailRetryPolicy{
+			MaxAttempts: cfg.Mail.MaxAttempts,
+			Backoff:     cfg.Mail.RetryBackoff,
+			MaxBackoff:  maxEmailBackoff,
+		}, cfg.Mail.QueueInterval)
+	}()
+	go func() {
+		defer workers.Done()
+		worker.PurgeIdempotencyKeys(workerCtx, db, cfg.Order.IdempotencyPurgeInterval)
+	}()
+
 	// inisiasi router
-	r, err := routes(db)
+	r, err := routes(cfg, db)
 	if err != nil {
 		fmt.Printf("Gagal membuat router: %v\n", err)
 		os.Exit(1)
@@ -39,13 +116,60 @@ func main() {
 
 	// buat server
 	server := &http.Server{
-		Addr:    ":8080",
-		Handler: r,
+		Addr:              cfg.HTTP.Addr,
+		Handler:           r,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+		MaxHeaderBytes:    cfg.HTTP.MaxHeaderBytes,
 	}
 
 	// jalankan server
-	if err = server.ListenAndServe(); err != nil {
-		fmt.Printf("Gagal menjalankan server: %v\n", err)
-		os.Exit(1)
+	serverErr := make(chan error, 1)
+	go func() {
+		fmt.Printf("Server berjalan di %s\n", cfg.HTTP.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	// tunggu sinyal shutdown atau server gagal berjalan
+	select {
+	case err = <-serverErr:
+		if err != nil {
+			fmt.Printf("Gagal menjalankan server: %v\n", err)
+			stopWorkers()
+			workers.Wait()
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+		fmt.Println("Menerima sinyal shutdown, menunggu request yang sedang berjalan")
 	}
+	stop()
+
+	// hentikan server dengan menunggu request yang sedang berjalan hingga batas waktu
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("Gagal menghentikan server dengan baik: %v\n", err)
+	}
+
+	// hentikan worker dan tunggu hingga selesai dalam batas waktu yang sama
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		fmt.Println("Batas waktu shutdown terlewati sebelum worker berhenti")
+	}
+
+	fmt.Println("Server berhenti")
 }
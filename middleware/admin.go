@@ -1,37 +1,79 @@
 package middleware
 
 import (
-	"os"
+	"database/sql"
+	"errors"
+	"time"
 
+	"github.com/fastcampus-backend-golang/online-shop/auth"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
 	"github.com/gin-gonic/gin"
 )
 
-func AdminOnly() gin.HandlerFunc {
+// AdminAudience adalah audience token yang diterbitkan untuk admin
+const AdminAudience = "admin"
+
+// adminKey adalah kunci gin.Context untuk menyimpan admin yang sedang login
+const adminKey = "admin"
+
+// AdminOnly digunakan untuk membatasi akses endpoint hanya untuk admin aktif dengan salah satu peran yang diberikan.
+// Super admin selalu diizinkan.
+func AdminOnly(admins repository.AdminRepository, secret string, roles ...model.AdminRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// ambil kunci rahasia admin
-		key := os.Getenv("ADMIN_SECRET")
-		if key == "" {
-			c.JSON(500, gin.H{"error": "Gagal memproses permintaan"})
-			c.Abort()
+		// ambil token dari header Authorization
+		token := auth.BearerToken(c.Request.Header.Get("Authorization"))
+		if token == "" {
+			response.Abort(c, model.ErrUnauthorized)
+			return
+		}
+
+		// verifikasi signature dan masa berlaku token
+		claims, err := auth.Verify(secret, token, AdminAudience, time.Now())
+		if err != nil {
+			response.Abort(c, model.ErrUnauthorized)
+			return
+		}
+
+		// ambil data admin terbaru agar perubahan peran & penonaktifan langsung berlaku
+		admin, err := admins.SelectAdminUserByID(claims.Subject)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Abort(c, model.ErrUnauthorized)
+				return
+			}
+
+			response.Abort(c, err)
 			return
 		}
 
-		// ambil header Authorization
-		auth := c.Request.Header.Get("Authorization")
-		if auth == "" {
-			c.JSON(401, gin.H{"error": "Akses tidak diizinkan"})
-			c.Abort()
+		if admin.IsActive == nil || !*admin.IsActive {
+			response.Abort(c, model.ErrUnauthorized)
 			return
 		}
 
-		// validasi kunci admin dengan header
-		if auth != key {
-			c.JSON(401, gin.H{"error": "Akses tidak diizinkan"})
-			c.Abort()
+		// validasi peran admin
+		if !admin.Role.Allows(roles...) {
+			response.Abort(c, model.ErrForbidden)
 			return
 		}
 
+		// simpan admin untuk digunakan handler selanjutnya
+		c.Set(adminKey, admin)
+
 		// melanjutkan ke handler selanjutnya
 		c.Next()
 	}
 }
+
+// CurrentAdmin digunakan untuk mengambil admin yang sedang login dari context
+func CurrentAdmin(c *gin.Context) (model.AdminUser, bool) {
+	value, ok := c.Get(adminKey)
+	if !ok {
+		return model.AdminUser{}, false
+	}
+
+	admin, ok := value.(model.AdminUser)
+	return admin, ok
+}
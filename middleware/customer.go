@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/auth"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CustomerAudience adalah audience token yang diterbitkan untuk pelanggan
+const CustomerAudience = "customer"
+
+// customerKey adalah kunci gin.Context untuk menyimpan pelanggan yang sedang login
+const customerKey = "customer"
+
+// CustomerOnly digunakan untuk membatasi akses endpoint hanya untuk pelanggan yang sudah login
+func CustomerOnly(customers repository.CustomerRepository, secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// ambil token dari header Authorization
+		token := auth.BearerToken(c.Request.Header.Get("Authorization"))
+		if token == "" {
+			response.Abort(c, model.ErrUnauthorized)
+			return
+		}
+
+		if !authenticateCustomer(c, customers, secret, token) {
+			return
+		}
+
+		// melanjutkan ke handler selanjutnya
+		c.Next()
+	}
+}
+
+// OptionalCustomer digunakan untuk endpoint yang dapat diakses tamu maupun pelanggan.
+// Jika header Authorization diisi, token harus valid.
+func OptionalCustomer(customers repository.CustomerRepository, secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// lanjutkan sebagai tamu jika tidak ada token
+		token := auth.BearerToken(c.Request.Header.Get("Authorization"))
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		if !authenticateCustomer(c, customers, secret, token) {
+			return
+		}
+
+		// melanjutkan ke handler selanjutnya
+		c.Next()
+	}
+}
+
+// authenticateCustomer digunakan untuk memverifikasi token pelanggan lalu menyimpan pelanggan di context.
+// Jika gagal, response sudah dikirim dan fungsi mengembalikan false.
+func authenticateCustomer(c *gin.Context, customers repository.CustomerRepository, secret string, token string) bool {
+	// verifikasi signature dan masa berlaku token
+	claims, err := auth.Verify(secret, token, CustomerAudience, time.Now())
+	if err != nil {
+		response.Abort(c, model.ErrUnauthorized)
+		return false
+	}
+
+	// pastikan akun pelanggan masih ada
+	customer, err := customers.SelectCustomerByID(claims.Subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Abort(c, model.ErrUnauthorized)
+			return false
+		}
+
+		response.Abort(c, err)
+		return false
+	}
+
+	// simpan pelanggan untuk digunakan handler selanjutnya
+	c.Set(customerKey, customer)
+	return true
+}
+
+// CurrentCustomer digunakan untuk mengambil pelanggan yang sedang login dari context
+func CurrentCustomer(c *gin.Context) (model.Customer, bool) {
+	value, ok := c.Get(customerKey)
+	if !ok {
+		return model.Customer{}, false
+	}
+
+	customer, ok := value.(model.Customer)
+	return customer, ok
+}
@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/i18n"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader adalah header berisi kunci unik dari client untuk request yang boleh diulang
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader menandai response yang diambil dari request sebelumnya
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyLockTimeout adalah batas waktu request dianggap macet (misalnya server berhenti di tengah proses)
+// sehingga kuncinya boleh dipakai ulang. Selama handler berjalan batas ini diperpanjang setiap setengahnya.
+const idempotencyLockTimeout = time.Minute
+
+// validIdempotencyKey membatasi kunci dari client, misalnya UUID
+var validIdempotencyKey = regexp.MustCompile(`^[\x21-\x7E]{1,255}$`)
+
+// Idempotency digunakan agar request yang diulang dengan Idempotency-Key yang sama tidak diproses dua kali.
+// Response request pertama disimpan selama ttl dan dikembalikan lagi untuk request ulang yang identik.
+// Kunci hanya berlaku untuk endpoint dan pelanggan yang sama, callerHeaders ikut menentukan identitas pemanggil.
+func Idempotency(keys repository.IdempotencyRepository, ttl time.Duration, callerHeaders ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// request tanpa kunci diproses seperti biasa
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if !validIdempotencyKey.MatchString(key) {
+			response.Abort(c, model.ErrInvalidIdempotencyKey)
+			return
+		}
+
+		// baca request body untuk sidik jari, lalu kembalikan agar dapat dibaca handler
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Abort(c, model.ErrUnreadableBody)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		keyHash, fingerprint := idempotencyFingerprint(c, key, body, callerHeaders)
+
+		// catat kunci sebagai sedang diproses, gagal berarti kunci sudah dipakai
+		now := time.Now()
+		reserved, err := keys.ReserveIdempotencyKey(model.IdempotencyKey{
+			KeyHash:     keyHash,
+			Fingerprint: fingerprint,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+			LockedUntil: now.Add(idempotencyLockTimeout),
+		})
+		if err != nil {
+			response.Abort(c, err)
+			return
+		}
+
+		if !reserved {
+			replayIdempotent(c, keys, key, keyHash, fingerprint)
+			return
+		}
+
+		// rekam response handler, kunci dilepas jika response tidak disimpan agar request dapat diulang
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+
+			if err := keys.ReleaseIdempotencyKey(keyHash, fingerprint); err != nil {
+				fmt.Printf("Gagal melepas Idempotency-Key: %v\n", err)
+			}
+		}()
+
+		// perpanjang kunci selama handler berjalan agar request yang lama tidak diproses ulang
+		stop := make(chan struct{})
+		go extendIdempotencyKey(keys, keyHash, fingerprint, stop)
+
+		// melanjutkan ke handler selanjutnya
+		c.Next()
+		close(stop)
+
+		// kesalahan server dan pembatasan percobaan bersifat sementara, tidak disimpan
+		status := recorder.Status()
+		if status >= 500 || status == 429 {
+			return
+		}
+
+		// response (misalnya passcode pesanan) dienkripsi dengan kunci dari client
+		sealed, err := sealResponse(key, recorder.body.Bytes())
+		if err != nil {
+			fmt.Printf("Gagal mengenkripsi response Idempotency-Key: %v\n", err)
+			return
+		}
+
+		if err := keys.CompleteIdempotencyKey(keyHash, fingerprint, status, recorder.Header().Get("Content-Type"), sealed); err != nil {
+			fmt.Printf("Gagal menyimpan response Idempotency-Key: %v\n", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// extendIdempotencyKey digunakan untuk memperpanjang batas waktu kunci secara berkala hingga stop ditutup
+func extendIdempotencyKey(keys repository.IdempotencyRepository, keyHash string, fingerprint string, stop <-chan struct{}) {
+	ticker := time.NewTicker(idempotencyLockTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if err := keys.ExtendIdempotencyKey(keyHash, fingerprint, now.Add(idempotencyLockTimeout)); err != nil {
+				fmt.Printf("Gagal memperpanjang Idempotency-Key: %v\n", err)
+			}
+		}
+	}
+}
+
+// replayIdempotent digunakan untuk menjawab request ulang dengan response yang tersimpan
+func replayIdempotent(c *gin.Context, keys repository.IdempotencyRepository, key string, keyHash string, fingerprint string) {
+	defer c.Abort()
+
+	stored, err := keys.SelectIdempotencyKey(keyHash)
+	if err != nil {
+		// kunci baru saja dilepas oleh request pertama yang gagal
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(c, model.ErrIdempotencyKeyInProgress)
+			return
+		}
+
+		response.Error(c, err)
+		return
+	}
+
+	// kunci yang sama tidak boleh dipakai untuk request yang berbeda
+	if !hmac.Equal([]byte(stored.Fingerprint), []byte(fingerprint)) {
+		response.Error(c, model.ErrIdempotencyKeyReused)
+		return
+	}
+
+	if stored.StatusCode == nil {
+		response.Error(c, model.ErrIdempotencyKeyInProgress)
+		return
+	}
+
+	body, err := openResponse(key, stored.Response)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(*stored.StatusCode, stored.ContentType, body)
+}
+
+// idempotencyFingerprint digunakan untuk menghitung hash kunci (per endpoint dan pemanggil) dan sidik jari request.
+// Sidik jari memakai HMAC dengan kunci dari client agar isi request (misalnya passcode) tidak dapat ditebak dari database.
+// Bahasa response ikut dihitung karena response yang disimpan (misalnya pesan error) sudah diterjemahkan.
+func idempotencyFingerprint(c *gin.Context, key string, body []byte, callerHeaders []string) (string, string) {
+	customerID := ""
+	if customer, ok := CurrentCustomer(c); ok {
+		customerID = customer.ID
+	}
+
+	scope := sha256.New()
+	fmt.Fprintf(scope, "%s\n%s %s\n%s", customerID, c.Request.Method, c.Request.URL.Path, key)
+
+	mac := hmac.New(sha256.New, []byte(key))
+	fmt.Fprintf(mac, "lang: %s\n", i18n.Language(c))
+	for _, header := range callerHeaders {
+		fmt.Fprintf(mac, "%s: %s\n", header, c.GetHeader(header))
+	}
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+
+	return hex.EncodeToString(scope.Sum(nil)), hex.EncodeToString(mac.Sum(nil))
+}
+
+// responseCipher digunakan untuk membuat AES-GCM dengan kunci turunan dari Idempotency-Key
+func responseCipher(key string) (cipher.AEAD, error) {
+	secret := sha256.Sum256([]byte("idempotency-response\n" + key))
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// sealResponse digunakan untuk mengenkripsi response yang akan disimpan
+func sealResponse(key string, plaintext []byte) ([]byte, error) {
+	aead, err := responseCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// openResponse digunakan untuk membuka response yang tersimpan
+func openResponse(key string, sealed []byte) ([]byte, error) {
+	aead, err := responseCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("response tersimpan tidak valid")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// responseRecorder digunakan untuk merekam body response sambil tetap mengirimkannya ke client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
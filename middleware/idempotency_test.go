@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyTestServer adalah router dengan satu endpoint yang memakai middleware Idempotency
+type idempotencyTestServer struct {
+	keys   *repository.MemoryIdempotencyRepository
+	router *gin.Engine
+	calls  atomic.Int32
+	status atomic.Int32 // status response handler, bawaan 201
+}
+
+func newIdempotencyTestServer(t *testing.T, handler gin.HandlerFunc) *idempotencyTestServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	s := &idempotencyTestServer{keys: repository.NewMemoryIdempotencyRepository(repository.NewMemoryStore())}
+	s.status.Store(http.StatusCreated)
+	if handler == nil {
+		handler = func(c *gin.Context) {
+			n := s.calls.Add(1)
+			c.JSON(int(s.status.Load()), gin.H{"call": n})
+		}
+	}
+
+	s.router = gin.New()
+	s.router.Use(Language())
+	s.router.POST("/checkout", Idempotency(s.keys, time.Hour), handler)
+	return s
+}
+
+// do digunakan untuk mengirim request dengan Idempotency-Key dan header tambahan
+func (s *idempotencyTestServer) do(key string, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/checkout", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, key)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// errorCode digunakan untuk mengambil kode error dari response
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response bukan JSON: %s", w.Body.String())
+	}
+	return body.Code
+}
+
+// reservedKey digunakan untuk membuat kunci yang sedang diproses untuk request ke /checkout dengan body tertentu
+func reservedKey(key string, body string, createdAt time.Time, lockedUntil time.Time) model.IdempotencyKey {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/checkout", nil)
+	keyHash, fingerprint := idempotencyFingerprint(c, key, []byte(body), nil)
+
+	return model.IdempotencyKey{
+		KeyHash:     keyHash,
+		Fingerprint: fingerprint,
+		CreatedAt:   createdAt,
+		ExpiresAt:   createdAt.Add(time.Hour),
+		LockedUntil: lockedUntil,
+	}
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	s := newIdempotencyTestServer(t, nil)
+
+	first := s.do("kunci-1", `{"a":1}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("status %d, seharusnya 201", first.Code)
+	}
+
+	second := s.do("kunci-1", `{"a":1}`)
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, seharusnya %d %s", second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("header %s tidak diisi pada replay", IdempotentReplayedHeader)
+	}
+	if n := s.calls.Load(); n != 1 {
+		t.Errorf("handler dipanggil %d kali, seharusnya 1", n)
+	}
+
+	// kunci lain diproses sebagai request baru
+	if w := s.do("kunci-2", `{"a":1}`); w.Header().Get(IdempotentReplayedHeader) != "" || s.calls.Load() != 2 {
+		t.Errorf("kunci berbeda tidak boleh di-replay")
+	}
+}
+
+func TestIdempotencyFingerprintMismatch(t *testing.T) {
+	s := newIdempotencyTestServer(t, nil)
+	s.do("kunci-1", `{"a":1}`)
+
+	// body berbeda
+	if w := s.do("kunci-1", `{"a":2}`); w.Code != http.StatusUnprocessableEntity || errorCode(t, w) != "idempotency_key_reused" {
+		t.Errorf("body berbeda: %d %s, seharusnya 422 idempotency_key_reused", w.Code, w.Body.String())
+	}
+
+	// bahasa response berbeda, response tersimpan sudah diterjemahkan
+	if w := s.do("kunci-1", `{"a":1}`, "Accept-Language", "en"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("bahasa berbeda: status %d, seharusnya 422", w.Code)
+	}
+
+	if n := s.calls.Load(); n != 1 {
+		t.Errorf("handler dipanggil %d kali, seharusnya 1", n)
+	}
+}
+
+func TestIdempotencyInFlightConflict(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s := newIdempotencyTestServer(t, func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- s.do("kunci-1", `{"a":1}`) }()
+	<-started
+
+	// request ulang selama request pertama masih diproses ditolak
+	if w := s.do("kunci-1", `{"a":1}`); w.Code != http.StatusConflict || errorCode(t, w) != "idempotency_key_in_progress" {
+		t.Errorf("request bersamaan: %d %s, seharusnya 409 idempotency_key_in_progress", w.Code, w.Body.String())
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Fatalf("request pertama: status %d, seharusnya 201", w.Code)
+	}
+
+	if w := s.do("kunci-1", `{"a":1}`); w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("request setelah selesai seharusnya di-replay: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestIdempotencyStaleTakeover(t *testing.T) {
+	s := newIdempotencyTestServer(t, nil)
+	now := time.Now()
+
+	// kunci yang batas waktunya sudah diperpanjang tidak boleh diambil alih meskipun dibuat lebih dari semenit lalu
+	reserved, err := s.keys.ReserveIdempotencyKey(reservedKey("kunci-aktif", `{"a":1}`, now.Add(-90*time.Second), now.Add(30*time.Second)))
+	if err != nil || !reserved {
+		t.Fatalf("gagal menyiapkan kunci: %v", err)
+	}
+	if w := s.do("kunci-aktif", `{"a":1}`); w.Code != http.StatusConflict || errorCode(t, w) != "idempotency_key_in_progress" {
+		t.Errorf("kunci aktif: %d %s, seharusnya 409 idempotency_key_in_progress", w.Code, w.Body.String())
+	}
+
+	// kunci macet (server berhenti di tengah request) boleh dipakai ulang
+	reserved, err = s.keys.ReserveIdempotencyKey(reservedKey("kunci-macet", `{"a":1}`, now.Add(-2*time.Minute), now.Add(-time.Minute)))
+	if err != nil || !reserved {
+		t.Fatalf("gagal menyiapkan kunci: %v", err)
+	}
+	if w := s.do("kunci-macet", `{"a":1}`); w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("kunci macet: %d %s, seharusnya diproses ulang", w.Code, w.Body.String())
+	}
+
+	if n := s.calls.Load(); n != 1 {
+		t.Errorf("handler dipanggil %d kali, seharusnya 1", n)
+	}
+}
+
+func TestIdempotencyDoesNotStoreTransientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		s := newIdempotencyTestServer(t, nil)
+
+		s.status.Store(int32(status))
+		if w := s.do("kunci-1", `{"a":1}`); w.Code != status {
+			t.Fatalf("status %d, seharusnya %d", w.Code, status)
+		}
+
+		// request ulang diproses lagi lalu response yang berhasil disimpan
+		s.status.Store(http.StatusCreated)
+		if w := s.do("kunci-1", `{"a":1}`); w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Errorf("%d: request ulang %d %s, seharusnya diproses ulang", status, w.Code, w.Body.String())
+		}
+		if w := s.do("kunci-1", `{"a":1}`); w.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Errorf("%d: response yang berhasil seharusnya di-replay", status)
+		}
+
+		if n := s.calls.Load(); n != 2 {
+			t.Errorf("%d: handler dipanggil %d kali, seharusnya 2", status, n)
+		}
+	}
+
+	// kesalahan client (4xx selain 429) disimpan dan di-replay
+	s := newIdempotencyTestServer(t, nil)
+	s.status.Store(http.StatusConflict)
+	s.do("kunci-1", `{"a":1}`)
+	if w := s.do("kunci-1", `{"a":1}`); w.Code != http.StatusConflict || w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("409 seharusnya di-replay: %d", w.Code)
+	}
+}
+
+func TestIdempotencyRejectsInvalidKey(t *testing.T) {
+	s := newIdempotencyTestServer(t, nil)
+
+	if w := s.do("kunci dengan spasi", `{}`); w.Code != http.StatusBadRequest || errorCode(t, w) != "invalid_idempotency_key" {
+		t.Errorf("kunci tidak valid: %d %s", w.Code, w.Body.String())
+	}
+	if n := s.calls.Load(); n != 0 {
+		t.Errorf("handler tidak boleh dipanggil, dipanggil %d kali", n)
+	}
+}
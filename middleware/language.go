@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/fastcampus-backend-golang/online-shop/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// Language digunakan untuk memilih bahasa pesan response dari parameter lang atau header Accept-Language
+func Language() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// pilih bahasa sekali per request, handler mengambilnya dengan i18n.Language
+		language := i18n.Language(c)
+		c.Header("Content-Language", language)
+		c.Header("Vary", "Accept-Language")
+
+		// melanjutkan ke handler selanjutnya
+		c.Next()
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader adalah header yang membawa ID request dari client atau proxy
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey adalah kunci gin.Context untuk menyimpan ID request
+const requestIDKey = "requestId"
+
+// validRequestID membatasi ID request dari client agar aman disimpan dan ditampilkan
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID digunakan untuk memberi setiap request sebuah ID, memakai header X-Request-ID jika valid
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// gunakan ID dari client jika valid, selain itu buat ID baru
+		id := c.Request.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.New().String()
+		}
+
+		// simpan ID request dan kembalikan di header response
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		// melanjutkan ke handler selanjutnya
+		c.Next()
+	}
+}
+
+// GetRequestID digunakan untuk mengambil ID request dari context
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+
+	"github.com/fastcampus-backend-golang/online-shop/migration"
 )
 
-// migrate digunakan untuk melakukan migrasi tabel database
+// migrate digunakan untuk menjalankan seluruh migrasi database yang belum dijalankan
 func migrate(db *sql.DB) error {
 	// jika db null, berikan error
 	if db == nil {
@@ -15,39 +19,54 @@ func migrate(db *sql.DB) error {
 	}
 
 	// lakukan migrasi database
-	if _, err := db.Exec(`
-	CREATE TABLE IF NOT EXISTS products (
-		id VARCHAR(36) PRIMARY KEY,
-		name VARCHAR(255) NOT NULL,
-		price BIGINT NOT NULL,
-		is_deleted BOOLEAN NOT NULL DEFAULT FALSE
-	);
-
-	CREATE TABLE IF NOT EXISTS orders (
-		id VARCHAR(36) PRIMARY KEY,
-		email VARCHAR(255) NOT NULL,
-		address VARCHAR NOT NULL,
-		passcode VARCHAR,
-		paid_at TIMESTAMP,
-		paid_bank VARCHAR(255),
-		paid_account_number VARCHAR(255),
-		grand_total BIGINT NOT NULL
-	);
-
-	CREATE TABLE IF NOT EXISTS order_details (
-		id VARCHAR(36) PRIMARY KEY,
-		order_id VARCHAR(36) NOT NULL,
-		product_id VARCHAR(36) NOT NULL,
-		quantity INT NOT NULL,
-		price BIGINT NOT NULL,
-		total BIGINT NOT NULL,
-		FOREIGN KEY (order_id) REFERENCES orders(id) ON UPDATE CASCADE ON DELETE RESTRICT,
-		FOREIGN KEY (product_id) REFERENCES products(id) ON UPDATE CASCADE ON DELETE RESTRICT
-	);
-	`); err != nil {
+	if err := migration.Up(context.Background(), db); err != nil {
 		fmt.Printf("Gagal melakukan migrasi database: %v\n", err)
 		return err
 	}
 
 	return nil
 }
+
+// runMigrateCommand digunakan untuk menjalankan subcommand migrate up|down|status|to N
+func runMigrateCommand(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New("penggunaan: migrate up|down|status|to N")
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		return migration.Up(ctx, db)
+	case "down":
+		return migration.Down(ctx, db)
+	case "to":
+		if len(args) < 2 {
+			return errors.New("penggunaan: migrate to N")
+		}
+
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("versi migrasi tidak valid: %s", args[1])
+		}
+
+		return migration.To(ctx, db, version)
+	case "status":
+		statuses, err := migration.List(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			appliedAt := "belum dijalankan"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-32s %s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("subcommand migrate tidak dikenal: %s", args[0])
+	}
+}
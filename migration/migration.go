@@ -0,0 +1,333 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID adalah kunci advisory lock PostgreSQL agar beberapa replika tidak menjalankan migrasi bersamaan
+const lockID int64 = 7231609420114
+
+var (
+	ErrChecksumMismatch = errors.New("checksum migrasi tidak sesuai dengan yang sudah dijalankan")
+	ErrUnknownVersion   = errors.New("versi migrasi tidak dikenal")
+	ErrMissingDown      = errors.New("migrasi tidak memiliki file down")
+)
+
+// Migration adalah satu langkah migrasi bernomor beserta query up dan down-nya
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status adalah keadaan sebuah migrasi di database
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load digunakan untuk membaca seluruh file migrasi yang tertanam di binary, terurut berdasarkan versi
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		// format nama file: <versi>_<nama>.<up|down>.sql
+		name := entry.Name()
+		base := strings.TrimSuffix(name, ".sql")
+		direction := path.Ext(base)
+		base = strings.TrimSuffix(base, direction)
+
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 || (direction != ".up" && direction != ".down") {
+			return nil, fmt.Errorf("nama file migrasi tidak valid: %s", name)
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("versi file migrasi tidak valid: %s", name)
+		}
+
+		content, err := files.ReadFile(path.Join("sql", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		} else if m.Name != parts[1] {
+			return nil, fmt.Errorf("versi migrasi %d digunakan oleh lebih dari satu nama", version)
+		}
+
+		if direction == ".up" {
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrasi %d tidak memiliki file up", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up digunakan untuk menjalankan seluruh migrasi yang belum dijalankan
+func Up(ctx context.Context, db *sql.DB) error {
+	migrations, err := Load()
+	if err != nil {
+		return err
+	}
+
+	return To(ctx, db, migrations[len(migrations)-1].Version)
+}
+
+// Down digunakan untuk membatalkan satu migrasi terakhir yang sudah dijalankan
+func Down(ctx context.Context, db *sql.DB) error {
+	return withLock(ctx, db, func(conn *sql.Conn) error {
+		migrations, applied, err := prepare(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[migrations[i].Version]; ok {
+				return runDown(ctx, conn, migrations[i])
+			}
+		}
+
+		return nil
+	})
+}
+
+// To digunakan untuk memigrasikan database naik atau turun hingga tepat di versi target.
+// Versi 0 berarti membatalkan seluruh migrasi.
+func To(ctx context.Context, db *sql.DB, target int) error {
+	return withLock(ctx, db, func(conn *sql.Conn) error {
+		migrations, applied, err := prepare(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if target != 0 && !hasVersion(migrations, target) {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+		}
+
+		// jalankan migrasi up yang belum dijalankan sampai versi target
+		for _, m := range migrations {
+			if m.Version > target {
+				break
+			}
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := runUp(ctx, conn, m); err != nil {
+				return err
+			}
+		}
+
+		// batalkan migrasi di atas versi target, mulai dari yang terbaru
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if m.Version <= target {
+				break
+			}
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if err := runDown(ctx, conn, m); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// List digunakan untuk mengambil status seluruh migrasi
+func List(ctx context.Context, db *sql.DB) ([]Status, error) {
+	var statuses []Status
+	err := withLock(ctx, db, func(conn *sql.Conn) error {
+		migrations, applied, err := prepare(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			status := Status{Migration: m}
+			if appliedAt, ok := applied[m.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// withLock digunakan untuk menjalankan fn di satu koneksi yang memegang advisory lock migrasi
+func withLock(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	if db == nil {
+		return errors.New("nilai db null")
+	}
+
+	// advisory lock terikat ke sesi, jadi gunakan satu koneksi yang sama hingga selesai
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	return fn(conn)
+}
+
+// prepare digunakan untuk menyiapkan tabel schema_migrations, memuat migrasi, dan memverifikasi checksum
+func prepare(ctx context.Context, conn *sql.Conn) ([]Migration, map[int]time.Time, error) {
+	if _, err := conn.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)
+	`); err != nil {
+		return nil, nil, err
+	}
+
+	migrations, err := Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	known := map[int]Migration{}
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var checksum string
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &checksum, &appliedAt); err != nil {
+			return nil, nil, err
+		}
+
+		// migrasi yang sudah dijalankan tidak boleh diubah isinya
+		m, ok := known[version]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %d sudah dijalankan namun filenya tidak ditemukan", ErrUnknownVersion, version)
+		}
+		if m.Checksum != checksum {
+			return nil, nil, fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, m.Version, m.Name)
+		}
+
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return migrations, applied, nil
+}
+
+// runUp digunakan untuk menjalankan satu migrasi up beserta pencatatannya dalam satu transaksi
+func runUp(ctx context.Context, conn *sql.Conn, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+		return fmt.Errorf("gagal menjalankan migrasi %04d_%s: %w", m.Version, m.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`, m.Version, m.Name, m.Checksum); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// runDown digunakan untuk membatalkan satu migrasi beserta pencatatannya dalam satu transaksi
+func runDown(ctx context.Context, conn *sql.Conn, m Migration) error {
+	if m.Down == "" {
+		return fmt.Errorf("%w: %04d_%s", ErrMissingDown, m.Version, m.Name)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+		return fmt.Errorf("gagal membatalkan migrasi %04d_%s: %w", m.Version, m.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func hasVersion(migrations []Migration, version int) bool {
+	for _, m := range migrations {
+		if m.Version == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_status_histories;
+ALTER TABLE orders DROP COLUMN IF EXISTS status;
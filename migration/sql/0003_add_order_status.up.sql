@@ -0,0 +1,12 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending';
+UPDATE orders SET status = 'paid' WHERE status = 'pending' AND paid_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS order_status_histories (
+	id VARCHAR(36) PRIMARY KEY,
+	order_id VARCHAR(36) NOT NULL,
+	from_status VARCHAR(20),
+	to_status VARCHAR(20) NOT NULL,
+	note VARCHAR,
+	changed_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (order_id) REFERENCES orders(id) ON UPDATE CASCADE ON DELETE RESTRICT
+);
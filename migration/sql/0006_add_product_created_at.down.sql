@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS products_created_at_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS categories (
+	id VARCHAR(36) PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	parent_id VARCHAR(36),
+	FOREIGN KEY (parent_id) REFERENCES categories(id) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE TABLE IF NOT EXISTS product_categories (
+	product_id VARCHAR(36) NOT NULL,
+	category_id VARCHAR(36) NOT NULL,
+	PRIMARY KEY (product_id, category_id),
+	FOREIGN KEY (product_id) REFERENCES products(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	FOREIGN KEY (category_id) REFERENCES categories(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_categories_category_id_idx ON product_categories (category_id);
@@ -0,0 +1,4 @@
+ALTER TABLE order_details DROP COLUMN IF EXISTS variant_options;
+ALTER TABLE order_details DROP COLUMN IF EXISTS sku;
+ALTER TABLE order_details DROP COLUMN IF EXISTS variant_id;
+DROP TABLE IF EXISTS product_variants;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+	id VARCHAR(36) PRIMARY KEY,
+	product_id VARCHAR(36) NOT NULL,
+	sku VARCHAR(64) NOT NULL UNIQUE,
+	options JSONB NOT NULL DEFAULT '{}',
+	price BIGINT,
+	stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+	is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+	FOREIGN KEY (product_id) REFERENCES products(id) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS product_variants_product_id_idx ON product_variants (product_id);
+
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS variant_id VARCHAR(36) REFERENCES product_variants(id) ON UPDATE CASCADE ON DELETE RESTRICT;
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+ALTER TABLE order_details ADD COLUMN IF NOT EXISTS variant_options JSONB;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS order_discounts;
+DROP TABLE IF EXISTS promo_code_categories;
+DROP TABLE IF EXISTS promo_code_products;
+DROP TABLE IF EXISTS promo_codes;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_total;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_fee;
+ALTER TABLE orders DROP COLUMN IF EXISTS subtotal;
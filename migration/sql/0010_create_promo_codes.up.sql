@@ -0,0 +1,48 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal BIGINT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_fee BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_total BIGINT NOT NULL DEFAULT 0;
+UPDATE orders SET subtotal = grand_total WHERE subtotal IS NULL;
+ALTER TABLE orders ALTER COLUMN subtotal SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS promo_codes (
+	id VARCHAR(36) PRIMARY KEY,
+	code VARCHAR(64) NOT NULL UNIQUE,
+	type VARCHAR(20) NOT NULL,
+	value BIGINT NOT NULL DEFAULT 0,
+	max_discount BIGINT,
+	min_order_amount BIGINT NOT NULL DEFAULT 0,
+	starts_at TIMESTAMP,
+	ends_at TIMESTAMP,
+	usage_limit INT,
+	usage_limit_per_email INT,
+	is_active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS promo_code_products (
+	promo_code_id VARCHAR(36) NOT NULL,
+	product_id VARCHAR(36) NOT NULL,
+	PRIMARY KEY (promo_code_id, product_id),
+	FOREIGN KEY (promo_code_id) REFERENCES promo_codes(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	FOREIGN KEY (product_id) REFERENCES products(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS promo_code_categories (
+	promo_code_id VARCHAR(36) NOT NULL,
+	category_id VARCHAR(36) NOT NULL,
+	PRIMARY KEY (promo_code_id, category_id),
+	FOREIGN KEY (promo_code_id) REFERENCES promo_codes(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	FOREIGN KEY (category_id) REFERENCES categories(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS order_discounts (
+	id VARCHAR(36) PRIMARY KEY,
+	order_id VARCHAR(36) NOT NULL,
+	promo_code_id VARCHAR(36) NOT NULL,
+	code VARCHAR(64) NOT NULL,
+	type VARCHAR(20) NOT NULL,
+	amount BIGINT NOT NULL,
+	FOREIGN KEY (order_id) REFERENCES orders(id) ON UPDATE CASCADE ON DELETE RESTRICT,
+	FOREIGN KEY (promo_code_id) REFERENCES promo_codes(id) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS order_discounts_promo_code_id_idx ON order_discounts (promo_code_id);
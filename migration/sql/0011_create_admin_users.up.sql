@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS admin_users (
+	id VARCHAR(36) PRIMARY KEY,
+	email VARCHAR(255) NOT NULL UNIQUE,
+	password_hash VARCHAR NOT NULL,
+	role VARCHAR(32) NOT NULL,
+	is_active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
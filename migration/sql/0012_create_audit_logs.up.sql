@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id VARCHAR(36) PRIMARY KEY,
+	actor_id VARCHAR(36),
+	actor_email VARCHAR(255),
+	action VARCHAR(64) NOT NULL,
+	entity_type VARCHAR(64) NOT NULL,
+	entity_id VARCHAR(36) NOT NULL,
+	before JSONB,
+	after JSONB,
+	diff JSONB NOT NULL DEFAULT '{}',
+	request_id VARCHAR(64),
+	client_ip VARCHAR(64),
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_actor_id_created_at_idx ON audit_logs (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS audit_logs_entity_created_at_idx ON audit_logs (entity_type, entity_id, created_at);
+CREATE INDEX IF NOT EXISTS audit_logs_created_at_idx ON audit_logs (created_at);
+
+-- audit log hanya boleh ditambah, tidak boleh diubah atau dihapus
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS TRIGGER AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs hanya boleh ditambah';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+CREATE TRIGGER audit_logs_append_only
+	BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS passcode_reset_tokens (
+	id VARCHAR(36) PRIMARY KEY,
+	order_id VARCHAR(36) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL UNIQUE,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	used_at TIMESTAMP,
+	FOREIGN KEY (order_id) REFERENCES orders(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS passcode_reset_tokens_order_id_created_at_idx ON passcode_reset_tokens (order_id, created_at);
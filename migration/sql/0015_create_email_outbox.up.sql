@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS email_outbox (
+	id VARCHAR(36) PRIMARY KEY,
+	recipient VARCHAR(255) NOT NULL,
+	subject VARCHAR(255) NOT NULL,
+	text_body TEXT NOT NULL,
+	html_body TEXT,
+	attempts INT NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP NOT NULL,
+	last_error TEXT,
+	sent_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS email_outbox_pending_idx ON email_outbox (next_attempt_at) WHERE sent_at IS NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS customers (
+	id VARCHAR(36) PRIMARY KEY,
+	email VARCHAR(255) NOT NULL,
+	password_hash VARCHAR NOT NULL,
+	name VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS customers_email_idx ON customers (LOWER(email));
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_id VARCHAR(36) REFERENCES customers(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS orders_customer_id_created_at_idx ON orders (customer_id, created_at);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS carts (
+	id VARCHAR(36) PRIMARY KEY,
+	customer_id VARCHAR(36) UNIQUE REFERENCES customers(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	token_hash VARCHAR(64),
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS cart_items (
+	id VARCHAR(36) PRIMARY KEY,
+	cart_id VARCHAR(36) NOT NULL REFERENCES carts(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	product_id VARCHAR(36) NOT NULL REFERENCES products(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	variant_id VARCHAR(36) REFERENCES product_variants(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	quantity INT NOT NULL CHECK (quantity > 0),
+	added_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS cart_items_cart_product_variant_idx ON cart_items (cart_id, product_id, (COALESCE(variant_id, '')));
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	key_hash VARCHAR(64) PRIMARY KEY,
+	fingerprint VARCHAR(64) NOT NULL,
+	status_code INT,
+	content_type VARCHAR(255),
+	response BYTEA,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
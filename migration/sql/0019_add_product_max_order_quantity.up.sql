@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS max_order_quantity INT CHECK (max_order_quantity > 0);
@@ -0,0 +1,232 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// AdminRole adalah peran admin yang menentukan endpoint admin yang boleh diakses
+type AdminRole string
+
+const (
+	AdminRoleCatalogManager AdminRole = "catalog_manager" // mengelola produk, varian, kategori, dan promo
+	AdminRoleOrderManager   AdminRole = "order_manager"   // mengelola status pesanan
+	AdminRoleSuperAdmin     AdminRole = "super_admin"     // seluruh akses termasuk mengelola admin
+)
+
+// Allows digunakan untuk memeriksa apakah peran boleh mengakses endpoint yang membutuhkan salah satu peran
+func (r AdminRole) Allows(roles ...AdminRole) bool {
+	if r == AdminRoleSuperAdmin {
+		return true
+	}
+
+	for _, role := range roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AdminUser adalah representasi dari data admin di database dan API
+type AdminUser struct {
+	ID           string    `json:"id" binding:"len=0"` // mencegah ID diisi oleh user
+	Email        string    `json:"email" binding:"required,email"`
+	Password     string    `json:"password,omitempty" binding:"omitempty,min=8,max=72"` // hanya untuk request, tidak pernah ditampilkan
+	PasswordHash string    `json:"-"`
+	Role         AdminRole `json:"role" binding:"required,oneof=catalog_manager order_manager super_admin"`
+	IsActive     *bool     `json:"isActive,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// AdminLogin adalah body request login admin
+type AdminLogin struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ErrDuplicateAdminEmail adalah error ketika email admin sudah digunakan
+var ErrDuplicateAdminEmail = NewError(ErrorKindConflict, "admin_email_taken", "Email admin sudah digunakan")
+
+var (
+	// ErrInvalidAdmin adalah error ketika data admin pada request tidak valid
+	ErrInvalidAdmin = NewError(ErrorKindValidation, "invalid_admin", "Data admin tidak valid")
+
+	// ErrAdminPasswordRequired adalah error ketika admin baru dibuat tanpa password
+	ErrAdminPasswordRequired = NewError(ErrorKindValidation, "admin_password_required", "Password admin wajib diisi")
+
+	// ErrAdminNotFound adalah error ketika admin tidak ditemukan
+	ErrAdminNotFound = NewError(ErrorKindNotFound, "admin_not_found", "Admin tidak ditemukan")
+
+	// ErrLastSuperAdmin adalah error ketika perubahan akan menghilangkan super admin aktif terakhir
+	ErrLastSuperAdmin = NewError(ErrorKindConflict, "last_super_admin", "Harus ada minimal satu super admin aktif")
+)
+
+// SelectAdminUser adalah fungsi untuk mengambil seluruh data admin dari database
+func SelectAdminUser(db *sql.DB) ([]AdminUser, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	rows, err := db.Query(`SELECT id, email, password_hash, role, is_active, created_at FROM admin_users ORDER BY email`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ubah data hasil query ke bentuk slice
+	admins := []AdminUser{}
+	for rows.Next() {
+		admin, err := scanAdminUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		admins = append(admins, admin)
+	}
+
+	return admins, rows.Err()
+}
+
+// SelectAdminUserByID adalah fungsi untuk mengambil data admin berdasarkan ID
+func SelectAdminUserByID(db *sql.DB, id string) (AdminUser, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return AdminUser{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	return scanAdminUser(db.QueryRow(`SELECT id, email, password_hash, role, is_active, created_at FROM admin_users WHERE id = $1`, id))
+}
+
+// SelectAdminUserByEmail adalah fungsi untuk mengambil data admin berdasarkan email
+func SelectAdminUserByEmail(db *sql.DB, email string) (AdminUser, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return AdminUser{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	return scanAdminUser(db.QueryRow(`SELECT id, email, password_hash, role, is_active, created_at FROM admin_users WHERE LOWER(email) = LOWER($1)`, email))
+}
+
+// InsertAdminUser adalah fungsi untuk menyimpan data admin beserta audit log-nya ke database
+func InsertAdminUser(db *sql.DB, admin AdminUser, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := `INSERT INTO admin_users (id, email, password_hash, role, is_active, created_at) VALUES ($1, $2, $3, $4, COALESCE($5, TRUE), $6)`
+	err := execWithAudit(db, audit, query, admin.ID, admin.Email, admin.PasswordHash, admin.Role, admin.IsActive, admin.CreatedAt)
+	return mapAdminError(err)
+}
+
+// UpdateAdminUser adalah fungsi untuk mengubah email, password, peran, dan status aktif admin beserta audit log-nya.
+// Perubahan yang menghilangkan super admin aktif terakhir ditolak dengan ErrLastSuperAdmin.
+func UpdateAdminUser(db *sql.DB, admin AdminUser, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// kunci seluruh super admin aktif agar perubahan bersamaan menunggu transaction ini selesai
+	superAdmins, err := lockActiveSuperAdmins(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// cegah super admin aktif terakhir kehilangan aksesnya
+	if err := admin.CheckLastSuperAdmin(superAdmins); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// eksekusi query beserta audit log-nya
+	query := `UPDATE admin_users SET email = $1, password_hash = $2, role = $3, is_active = COALESCE($4, is_active) WHERE id = $5`
+	if err := execTxWithAudit(tx, audit, query, admin.Email, admin.PasswordHash, admin.Role, admin.IsActive, admin.ID); err != nil {
+		tx.Rollback()
+		return mapAdminError(err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return mapAdminError(err)
+	}
+
+	return nil
+}
+
+// lockActiveSuperAdmins adalah fungsi untuk mengunci dan mengambil ID seluruh super admin aktif di dalam transaction
+func lockActiveSuperAdmins(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query(`SELECT id FROM admin_users WHERE role = $1 AND is_active ORDER BY id FOR UPDATE`, AdminRoleSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// CheckLastSuperAdmin digunakan untuk memastikan perubahan admin (IsActive nil berarti status aktif tidak diubah)
+// tidak menghilangkan super admin aktif terakhir. activeSuperAdmins berisi ID super admin aktif sebelum perubahan.
+func (a AdminUser) CheckLastSuperAdmin(activeSuperAdmins []string) error {
+	// admin tetap menjadi super admin aktif
+	if a.Role == AdminRoleSuperAdmin && (a.IsActive == nil || *a.IsActive) {
+		return nil
+	}
+
+	// admin yang bukan super admin aktif tidak mengurangi jumlahnya
+	if !slices.Contains(activeSuperAdmins, a.ID) {
+		return nil
+	}
+
+	if len(activeSuperAdmins) <= 1 {
+		return ErrLastSuperAdmin
+	}
+
+	return nil
+}
+
+func scanAdminUser(row rowScanner) (AdminUser, error) {
+	var admin AdminUser
+	var isActive bool
+	if err := row.Scan(&admin.ID, &admin.Email, &admin.PasswordHash, &admin.Role, &isActive, &admin.CreatedAt); err != nil {
+		return AdminUser{}, err
+	}
+
+	admin.IsActive = &isActive
+	return admin, nil
+}
+
+func mapAdminError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicateAdminEmail
+	}
+
+	return err
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAdminUserCheckLastSuperAdmin(t *testing.T) {
+	active, inactive := true, false
+
+	for name, tc := range map[string]struct {
+		admin       AdminUser
+		superAdmins []string
+		want        error
+	}{
+		"super admin terakhir diturunkan":    {AdminUser{ID: "a1", Role: AdminRoleOrderManager}, []string{"a1"}, ErrLastSuperAdmin},
+		"super admin terakhir dinonaktifkan": {AdminUser{ID: "a1", Role: AdminRoleSuperAdmin, IsActive: &inactive}, []string{"a1"}, ErrLastSuperAdmin},
+		"masih ada super admin lain":         {AdminUser{ID: "a1", Role: AdminRoleOrderManager}, []string{"a1", "a2"}, nil},
+		"tetap super admin aktif":            {AdminUser{ID: "a1", Role: AdminRoleSuperAdmin, IsActive: &active}, []string{"a1"}, nil},
+		"status aktif tidak diubah":          {AdminUser{ID: "a1", Role: AdminRoleSuperAdmin}, []string{"a1"}, nil},
+		"bukan super admin aktif":            {AdminUser{ID: "a2", Role: AdminRoleCatalogManager, IsActive: &inactive}, []string{"a1"}, nil},
+	} {
+		if err := tc.admin.CheckLastSuperAdmin(tc.superAdmins); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, seharusnya %v", name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package model
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditLog adalah representasi dari catatan perubahan data oleh admin di database dan API
+type AuditLog struct {
+	ID         string          `json:"id"`
+	ActorID    *string         `json:"actorId"`
+	ActorEmail *string         `json:"actorEmail"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	Diff       json.RawMessage `json:"diff"`
+	RequestID  string          `json:"requestId"`
+	ClientIP   string          `json:"clientIp"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// AuditFilter adalah representasi dari parameter query untuk daftar audit log di API
+type AuditFilter struct {
+	ActorID    string     `form:"actor"`
+	EntityType string     `form:"entity"`
+	EntityID   string     `form:"entityId"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page       int        `form:"page" binding:"omitempty,min=1"`
+	Limit      int        `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// AuditLogList adalah representasi dari daftar audit log beserta metadata halaman di API
+type AuditLogList struct {
+	Data []AuditLog `json:"data"`
+	Meta PageMeta   `json:"meta"`
+}
+
+// ErrInvalidTimeRange adalah error ketika waktu awal filter melebihi waktu akhir
+var ErrInvalidTimeRange = NewError(ErrorKindValidation, "invalid_time_range", "Waktu awal tidak boleh melebihi waktu akhir")
+
+// auditChange adalah nilai sebelum dan sesudah dari satu field yang berubah
+type auditChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// SetAuditState digunakan untuk mengisi data sebelum, sesudah, dan selisih field yang berubah.
+// Nilai nil berarti data belum ada (create) atau sudah tidak ada (delete).
+func (l *AuditLog) SetAuditState(before, after any) error {
+	var err error
+	if l.Before, err = marshalAuditState(before); err != nil {
+		return err
+	}
+
+	if l.After, err = marshalAuditState(after); err != nil {
+		return err
+	}
+
+	l.Diff, err = diffAuditState(l.Before, l.After)
+	return err
+}
+
+func marshalAuditState(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(state)
+}
+
+// diffAuditState digunakan untuk membandingkan field level pertama dari dua objek JSON
+func diffAuditState(before, after json.RawMessage) (json.RawMessage, error) {
+	beforeFields := map[string]json.RawMessage{}
+	afterFields := map[string]json.RawMessage{}
+
+	if before != nil {
+		if err := json.Unmarshal(before, &beforeFields); err != nil {
+			return nil, err
+		}
+	}
+
+	if after != nil {
+		if err := json.Unmarshal(after, &afterFields); err != nil {
+			return nil, err
+		}
+	}
+
+	diff := map[string]auditChange{}
+	for key, value := range beforeFields {
+		if !bytes.Equal(value, afterFields[key]) {
+			diff[key] = auditChange{Before: value, After: afterFields[key]}
+		}
+	}
+
+	for key, value := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			diff[key] = auditChange{After: value}
+		}
+	}
+
+	return json.Marshal(diff)
+}
+
+// insertAuditLog adalah fungsi untuk menyimpan audit log di dalam transaction perubahan yang dicatat.
+// Audit log nil berarti perubahan tersebut tidak perlu dicatat.
+func insertAuditLog(tx *sql.Tx, log *AuditLog) error {
+	if log == nil {
+		return nil
+	}
+
+	// eksekusi query
+	query := `INSERT INTO audit_logs (id, actor_id, actor_email, action, entity_type, entity_id, before, after, diff, request_id, client_ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err := tx.Exec(query, log.ID, log.ActorID, log.ActorEmail, log.Action, log.EntityType, log.EntityID,
+		nullableJSON(log.Before), nullableJSON(log.After), nullableJSON(log.Diff), log.RequestID, log.ClientIP, log.CreatedAt)
+
+	return err
+}
+
+// execWithAudit adalah fungsi untuk menjalankan satu query perubahan data beserta audit log-nya dalam satu transaction.
+// Jika audit log diisi namun tidak ada baris yang berubah, transaction dibatalkan dan sql.ErrNoRows dikembalikan.
+func execWithAudit(db *sql.DB, audit *AuditLog, query string, args ...any) error {
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// eksekusi query perubahan data dan simpan audit log
+	if err := execTxWithAudit(tx, audit, query, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// execTxWithAudit adalah fungsi untuk menjalankan satu query perubahan data beserta audit log-nya di dalam transaction.
+// Jika audit log diisi namun tidak ada baris yang berubah, sql.ErrNoRows dikembalikan.
+func execTxWithAudit(tx *sql.Tx, audit *AuditLog, query string, args ...any) error {
+	// eksekusi query perubahan data
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	if audit != nil {
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			return sql.ErrNoRows
+		}
+	}
+
+	// simpan audit log
+	return insertAuditLog(tx, audit)
+}
+
+// SelectAuditLog adalah fungsi untuk mengambil audit log dari database sesuai filter dan halaman, terbaru lebih dulu
+func SelectAuditLog(db *sql.DB, filter AuditFilter) ([]AuditLog, int, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, 0, errors.New("tidak ada koneksi ke database")
+	}
+
+	// susun kondisi filter
+	conditions := []string{"TRUE"}
+	args := []any{}
+
+	if filter.ActorID != "" {
+		args = append(args, filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+	}
+
+	if filter.EntityType != "" {
+		args = append(args, filter.EntityType)
+		conditions = append(conditions, fmt.Sprintf("entity_type = $%d", len(args)))
+	}
+
+	if filter.EntityID != "" {
+		args = append(args, filter.EntityID)
+		conditions = append(conditions, fmt.Sprintf("entity_id = $%d", len(args)))
+	}
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	// hitung total audit log yang sesuai filter
+	var total int
+	if err := db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM audit_logs WHERE %s`, where), args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// query untuk mengambil data audit log
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf(`SELECT id, actor_id, actor_email, action, entity_type, entity_id, before, after, diff, COALESCE(request_id, ''), COALESCE(client_ip, ''), created_at
+		FROM audit_logs WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+
+	// eksekusi query
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// ubah data hasil query ke bentuk slice
+	logs := []AuditLog{}
+	for rows.Next() {
+		var log AuditLog
+		var before, after, diff []byte
+		err = rows.Scan(&log.ID, &log.ActorID, &log.ActorEmail, &log.Action, &log.EntityType, &log.EntityID,
+			&before, &after, &diff, &log.RequestID, &log.ClientIP, &log.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		log.Before, log.After, log.Diff = rawJSON(before), rawJSON(after), rawJSON(diff)
+		logs = append(logs, log)
+	}
+
+	return logs, total, rows.Err()
+}
+
+// nullableJSON digunakan agar JSON kosong disimpan sebagai NULL
+func nullableJSON(value json.RawMessage) any {
+	if value == nil {
+		return nil
+	}
+
+	return string(value)
+}
+
+func rawJSON(value []byte) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+
+	return json.RawMessage(value)
+}
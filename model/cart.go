@@ -0,0 +1,306 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Cart adalah representasi dari keranjang belanja di database dan API.
+// Keranjang dimiliki pelanggan yang login atau diakses tamu dengan token keranjang.
+type Cart struct {
+	ID         string     `json:"id"`
+	CustomerID *string    `json:"customerId,omitempty"`
+	TokenHash  *string    `json:"-"`
+	Token      string     `json:"token,omitempty"` // hanya ditampilkan saat keranjang tamu dibuat
+	Items      []CartItem `json:"items"`
+	Subtotal   int64      `json:"subtotal"`  // dihitung ulang dari harga produk saat ini
+	Available  bool       `json:"available"` // false jika ada item yang tidak dapat dipesan
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// CartItem adalah representasi dari item di keranjang belanja.
+// Nama, harga, dan ketersediaan tidak disimpan, melainkan dihitung ulang setiap keranjang ditampilkan.
+type CartItem struct {
+	ID        string         `json:"id"`
+	CartID    string         `json:"-"`
+	ProductID string         `json:"productId"`
+	VariantID *string        `json:"variantId,omitempty"`
+	Quantity  int32          `json:"quantity"`
+	AddedAt   time.Time      `json:"addedAt"`
+	Name      string         `json:"name"`
+	SKU       *string        `json:"sku,omitempty"`
+	Options   VariantOptions `json:"options,omitempty"`
+	Price     int64          `json:"price"`
+	Total     int64          `json:"total"`
+	Available bool           `json:"available"`
+	IssueCode string         `json:"issueCode,omitempty"` // kode alasan item tidak dapat dipesan (katalog field.<kode>)
+	Issue     string         `json:"issue,omitempty"`     // alasan item tidak dapat dipesan
+}
+
+// AddCartItem adalah body request untuk menambahkan produk ke keranjang
+type AddCartItem struct {
+	ProductID string `json:"productId" binding:"required"`
+	VariantID string `json:"variantId"` // wajib diisi jika produk memiliki varian
+	Quantity  int32  `json:"quantity" binding:"required,min=1"`
+}
+
+// UpdateCartItem adalah body request untuk mengubah jumlah item di keranjang
+type UpdateCartItem struct {
+	Quantity int32 `json:"quantity" binding:"required,min=1"`
+}
+
+// CartCheckout adalah keranjang yang di-checkout beserta item yang dibaca saat checkout.
+// Item tersebut dihapus di transaction yang sama dengan pembuatan pesanan.
+type CartCheckout struct {
+	CartID string
+	Items  []CartItem
+}
+
+// ErrCustomerCartExists adalah error ketika pelanggan sudah memiliki keranjang
+var ErrCustomerCartExists = NewError(ErrorKindConflict, "customer_cart_exists", "Pelanggan sudah memiliki keranjang")
+
+var (
+	// ErrCartNotFound adalah error ketika keranjang tidak ditemukan atau tidak boleh diakses
+	ErrCartNotFound = NewError(ErrorKindNotFound, "cart_not_found", "Keranjang tidak ditemukan")
+
+	// ErrCartItemNotFound adalah error ketika item tidak ada di keranjang
+	ErrCartItemNotFound = NewError(ErrorKindNotFound, "cart_item_not_found", "Item keranjang tidak ditemukan")
+
+	// ErrInvalidCartItem adalah error ketika data item keranjang pada request tidak valid
+	ErrInvalidCartItem = NewError(ErrorKindValidation, "invalid_cart_item", "Data item keranjang tidak valid")
+
+	// ErrCartChanged adalah error ketika isi keranjang berubah (misalnya sudah di-checkout request lain) selama checkout
+	ErrCartChanged = NewError(ErrorKindConflict, "cart_changed", "Isi keranjang berubah saat checkout, silakan ulangi")
+)
+
+// InsertCart adalah fungsi untuk menyimpan keranjang baru ke database
+func InsertCart(db *sql.DB, cart Cart) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := `INSERT INTO carts (id, customer_id, token_hash, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := db.Exec(query, cart.ID, cart.CustomerID, cart.TokenHash, cart.CreatedAt, cart.UpdatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrCustomerCartExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// SelectCartByID adalah fungsi untuk mengambil keranjang (tanpa item) berdasarkan ID
+func SelectCartByID(db *sql.DB, id string) (Cart, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return Cart{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	return scanCart(db.QueryRow(`SELECT id, customer_id, token_hash, created_at, updated_at FROM carts WHERE id = $1`, id))
+}
+
+// SelectCartByCustomerID adalah fungsi untuk mengambil keranjang (tanpa item) milik pelanggan
+func SelectCartByCustomerID(db *sql.DB, customerID string) (Cart, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return Cart{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	return scanCart(db.QueryRow(`SELECT id, customer_id, token_hash, created_at, updated_at FROM carts WHERE customer_id = $1`, customerID))
+}
+
+// SelectCartItems adalah fungsi untuk mengambil item keranjang, urut sesuai waktu ditambahkan
+func SelectCartItems(db *sql.DB, cartID string) ([]CartItem, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	rows, err := db.Query(`SELECT id, cart_id, product_id, variant_id, quantity, added_at FROM cart_items WHERE cart_id = $1 ORDER BY added_at, id`, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ubah data hasil query ke bentuk slice
+	items := []CartItem{}
+	for rows.Next() {
+		var item CartItem
+		if err := rows.Scan(&item.ID, &item.CartID, &item.ProductID, &item.VariantID, &item.Quantity, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// UpsertCartItem adalah fungsi untuk menambahkan item ke keranjang.
+// Jika produk/varian yang sama sudah ada, jumlahnya ditambahkan.
+func UpsertCartItem(db *sql.DB, item CartItem) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// perbarui keranjang terlebih dahulu agar baris keranjang dikunci sebelum item (urutan yang sama dengan checkout)
+	if err := touchCart(tx, item.CartID, item.AddedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// eksekusi query
+	query := `INSERT INTO cart_items (id, cart_id, product_id, variant_id, quantity, added_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (cart_id, product_id, (COALESCE(variant_id, ''))) DO UPDATE SET quantity = LEAST(cart_items.quantity::BIGINT + EXCLUDED.quantity, 2147483647)`
+	if _, err := tx.Exec(query, item.ID, item.CartID, item.ProductID, item.VariantID, item.Quantity, item.AddedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// UpdateCartItemQuantity adalah fungsi untuk mengubah jumlah item di keranjang
+func UpdateCartItemQuantity(db *sql.DB, cartID string, itemID string, quantity int32, now time.Time) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// kunci keranjang sebelum item, seperti UpsertCartItem
+	if err := touchCart(tx, cartID, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result, err := tx.Exec(`UPDATE cart_items SET quantity = $1 WHERE cart_id = $2 AND id = $3`, quantity, cartID, itemID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCartItem adalah fungsi untuk menghapus item dari keranjang
+func DeleteCartItem(db *sql.DB, cartID string, itemID string, now time.Time) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// kunci keranjang sebelum item, seperti UpsertCartItem
+	if err := touchCart(tx, cartID, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result, err := tx.Exec(`DELETE FROM cart_items WHERE cart_id = $1 AND id = $2`, cartID, itemID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// checkoutCart adalah fungsi untuk mengunci keranjang lalu menghapus item yang di-checkout di dalam transaction.
+// Item dihapus sesuai ID dan jumlah yang dibaca saat checkout, sehingga checkout ganda atau perubahan
+// keranjang di tengah checkout menggagalkan transaction dan item yang ditambahkan setelahnya tidak ikut terhapus.
+func checkoutCart(tx *sql.Tx, cart CartCheckout, now time.Time) error {
+	// kunci keranjang, perubahan item keranjang lain menunggu hingga transaction selesai
+	var id string
+	if err := tx.QueryRow(`SELECT id FROM carts WHERE id = $1 FOR UPDATE`, cart.CartID).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCartNotFound
+		}
+
+		return err
+	}
+
+	for _, item := range cart.Items {
+		result, err := tx.Exec(`DELETE FROM cart_items WHERE cart_id = $1 AND id = $2 AND quantity = $3`, cart.CartID, item.ID, item.Quantity)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected != 1 {
+			return ErrCartChanged
+		}
+	}
+
+	return touchCart(tx, cart.CartID, now)
+}
+
+// touchCart digunakan untuk memperbarui waktu perubahan terakhir keranjang
+func touchCart(tx *sql.Tx, cartID string, now time.Time) error {
+	_, err := tx.Exec(`UPDATE carts SET updated_at = $1 WHERE id = $2`, now, cartID)
+	return err
+}
+
+func scanCart(row rowScanner) (Cart, error) {
+	var cart Cart
+	if err := row.Scan(&cart.ID, &cart.CustomerID, &cart.TokenHash, &cart.CreatedAt, &cart.UpdatedAt); err != nil {
+		return Cart{}, err
+	}
+
+	return cart, nil
+}
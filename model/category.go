@@ -0,0 +1,261 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Category adalah representasi dari data kategori produk di database dan API
+type Category struct {
+	ID       string     `json:"id" binding:"len=0"` // mencegah ID diisi oleh user
+	Name     string     `json:"name"`
+	ParentID *string    `json:"parentId"`
+	Children []Category `json:"children,omitempty" binding:"len=0"`
+}
+
+// ErrCategoryNotFound adalah error ketika kategori yang dirujuk tidak ditemukan
+var ErrCategoryNotFound = NewError(ErrorKindNotFound, "category_not_found", "Kategori tidak ditemukan")
+
+// ErrCategoryCycle adalah error ketika induk kategori merujuk ke dirinya sendiri atau turunannya
+var ErrCategoryCycle = NewError(ErrorKindValidation, "category_cycle", "Induk kategori tidak boleh kategori itu sendiri atau turunannya")
+
+// ErrCategoryHasChildren adalah error ketika kategori yang dihapus masih memiliki sub-kategori
+var ErrCategoryHasChildren = NewError(ErrorKindConflict, "category_has_children", "Kategori masih memiliki sub-kategori")
+
+var (
+	// ErrInvalidCategory adalah error ketika data kategori pada request tidak valid
+	ErrInvalidCategory = NewError(ErrorKindValidation, "invalid_category", "Data kategori tidak valid")
+
+	// ErrCategoryNameRequired adalah error ketika nama kategori kosong
+	ErrCategoryNameRequired = NewError(ErrorKindValidation, "category_name_required", "Nama kategori wajib diisi")
+
+	// ErrParentCategoryNotFound adalah error ketika induk kategori pada request tidak ditemukan
+	ErrParentCategoryNotFound = NewError(ErrorKindValidation, "parent_category_not_found", "Induk kategori tidak ditemukan")
+)
+
+// SelectCategory adalah fungsi untuk mengambil seluruh data kategori dari database
+func SelectCategory(db *sql.DB) ([]Category, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk mengambil data kategori
+	query := `SELECT id, name, parent_id FROM categories ORDER BY name, id`
+
+	// eksekusi query
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ubah data hasil query ke bentuk slice
+	categories := []Category{}
+	for rows.Next() {
+		category := Category{}
+		err = rows.Scan(&category.ID, &category.Name, &category.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+// BuildCategoryTree digunakan untuk menyusun daftar kategori menjadi pohon berdasarkan induknya
+func BuildCategoryTree(categories []Category) []Category {
+	// kelompokkan kategori berdasarkan induknya
+	children := make(map[string][]Category)
+	roots := []Category{}
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	// isi sub-kategori secara rekursif
+	var attach func(nodes []Category) []Category
+	attach = func(nodes []Category) []Category {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+
+		return nodes
+	}
+
+	return attach(roots)
+}
+
+// SelectCategoryByID adalah fungsi untuk mengambil data kategori berdasarkan ID dari database
+func SelectCategoryByID(db *sql.DB, id string) (Category, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return Category{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk mengambil data kategori berdasarkan ID
+	query := `SELECT id, name, parent_id FROM categories WHERE id = $1`
+
+	// eksekusi query
+	category := Category{}
+	err := db.QueryRow(query, id).Scan(&category.ID, &category.Name, &category.ParentID)
+	if err != nil {
+		return Category{}, err
+	}
+
+	return category, nil
+}
+
+// InsertCategory adalah fungsi untuk menyimpan data kategori beserta audit log-nya ke database
+func InsertCategory(db *sql.DB, category Category, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// pastikan induk kategori ada
+	if category.ParentID != nil {
+		if _, err := SelectCategoryByID(db, *category.ParentID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrCategoryNotFound
+			}
+			return err
+		}
+	}
+
+	// query untuk insert data kategori
+	query := `INSERT INTO categories (id, name, parent_id) VALUES ($1, $2, $3)`
+
+	// eksekusi query
+	return execWithAudit(db, audit, query, category.ID, category.Name, category.ParentID)
+}
+
+// UpdateCategory adalah fungsi untuk mengubah data kategori beserta audit log-nya di database
+func UpdateCategory(db *sql.DB, category Category, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// pastikan induk kategori ada dan bukan turunan dari kategori ini
+	if category.ParentID != nil {
+		query := `
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+		)
+		SELECT EXISTS (SELECT 1 FROM tree WHERE id = $2), EXISTS (SELECT 1 FROM categories WHERE id = $2)`
+
+		var isDescendant, parentExists bool
+		if err := db.QueryRow(query, category.ID, *category.ParentID).Scan(&isDescendant, &parentExists); err != nil {
+			return err
+		}
+
+		if !parentExists {
+			return ErrCategoryNotFound
+		}
+
+		if isDescendant {
+			return ErrCategoryCycle
+		}
+	}
+
+	// query untuk update data kategori
+	query := `UPDATE categories SET name = $1, parent_id = $2 WHERE id = $3`
+
+	// eksekusi query
+	return execWithAudit(db, audit, query, category.Name, category.ParentID, category.ID)
+}
+
+// DeleteCategory adalah fungsi untuk menghapus data kategori dari database dan menyimpan audit log-nya
+func DeleteCategory(db *sql.DB, id string, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// pastikan kategori tidak memiliki sub-kategori
+	var hasChildren bool
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)`, id).Scan(&hasChildren); err != nil {
+		return err
+	}
+
+	if hasChildren {
+		return ErrCategoryHasChildren
+	}
+
+	// query untuk menghapus data kategori, relasi produk ikut terhapus (ON DELETE CASCADE)
+	query := `DELETE FROM categories WHERE id = $1`
+
+	// eksekusi query
+	return execWithAudit(db, audit, query, id)
+}
+
+// SelectProductCategoryIDs adalah fungsi untuk mengambil ID kategori yang dimiliki sebuah produk
+func SelectProductCategoryIDs(db *sql.DB, productID string) ([]string, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk mengambil ID kategori produk
+	query := `SELECT category_id FROM product_categories WHERE product_id = $1 ORDER BY category_id`
+
+	// eksekusi query
+	rows, err := db.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ubah data hasil query ke bentuk slice
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// replaceProductCategories adalah fungsi untuk mengganti seluruh kategori yang dimiliki sebuah produk di dalam transaction
+func replaceProductCategories(tx *sql.Tx, productID string, categoryIDs []string) error {
+	// hapus relasi kategori sebelumnya
+	if _, err := tx.Exec(`DELETE FROM product_categories WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+
+	// simpan relasi kategori baru
+	query := `INSERT INTO product_categories (product_id, category_id) SELECT $1, id FROM categories WHERE id = $2 ON CONFLICT DO NOTHING`
+	for _, categoryID := range categoryIDs {
+		result, err := tx.Exec(query, productID, categoryID)
+		if err != nil {
+			return err
+		}
+
+		// kategori yang tidak ada tidak akan menghasilkan baris baru
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			var exists bool
+			if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1)`, categoryID).Scan(&exists); err != nil {
+				return err
+			}
+
+			if !exists {
+				return ErrCategoryNotFound
+			}
+		}
+	}
+
+	return nil
+}
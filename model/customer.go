@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Customer adalah representasi dari data akun pelanggan di database dan API
+type Customer struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// CustomerRegister adalah body request pendaftaran akun pelanggan
+type CustomerRegister struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Name     string `json:"name" binding:"max=255"`
+}
+
+// CustomerLogin adalah body request login pelanggan
+type CustomerLogin struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// AttachOrder adalah body request untuk menautkan pesanan tamu ke akun pelanggan
+type AttachOrder struct {
+	OrderID  string `json:"orderId" binding:"required"`
+	Passcode string `json:"passcode" binding:"required"`
+}
+
+// ErrDuplicateCustomerEmail adalah error ketika email pelanggan sudah terdaftar
+var ErrDuplicateCustomerEmail = NewError(ErrorKindConflict, "customer_email_taken", "Email sudah terdaftar")
+
+// ErrInvalidRegistration adalah error ketika data pendaftaran pelanggan tidak valid
+var ErrInvalidRegistration = NewError(ErrorKindValidation, "invalid_registration", "Data pendaftaran tidak valid")
+
+// SelectCustomerByID adalah fungsi untuk mengambil data pelanggan berdasarkan ID
+func SelectCustomerByID(db *sql.DB, id string) (Customer, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return Customer{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	return scanCustomer(db.QueryRow(`SELECT id, email, name, password_hash, created_at FROM customers WHERE id = $1`, id))
+}
+
+// SelectCustomerByEmail adalah fungsi untuk mengambil data pelanggan berdasarkan email
+func SelectCustomerByEmail(db *sql.DB, email string) (Customer, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return Customer{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	return scanCustomer(db.QueryRow(`SELECT id, email, name, password_hash, created_at FROM customers WHERE LOWER(email) = LOWER($1)`, email))
+}
+
+// InsertCustomer adalah fungsi untuk menyimpan data pelanggan ke database
+func InsertCustomer(db *sql.DB, customer Customer) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := `INSERT INTO customers (id, email, name, password_hash, created_at) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := db.Exec(query, customer.ID, customer.Email, customer.Name, customer.PasswordHash, customer.CreatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateCustomerEmail
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func scanCustomer(row rowScanner) (Customer, error) {
+	var customer Customer
+	if err := row.Scan(&customer.ID, &customer.Email, &customer.Name, &customer.PasswordHash, &customer.CreatedAt); err != nil {
+		return Customer{}, err
+	}
+
+	return customer, nil
+}
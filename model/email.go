@@ -0,0 +1,131 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Email adalah representasi dari email yang menunggu dikirim di antrean (outbox) database
+type Email struct {
+	ID            string
+	To            string
+	Subject       string
+	Text          string
+	HTML          string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+}
+
+// EmailRetryPolicy adalah aturan percobaan ulang pengiriman email yang gagal
+type EmailRetryPolicy struct {
+	MaxAttempts int           // batas percobaan, setelah itu email tidak dicoba lagi
+	Backoff     time.Duration // jeda sebelum percobaan ulang pertama, berlipat dua setiap kegagalan
+	MaxBackoff  time.Duration // jeda terlama antar percobaan
+}
+
+// NextAttempt digunakan untuk menghitung waktu percobaan berikutnya setelah percobaan ke-attempts gagal
+func (p EmailRetryPolicy) NextAttempt(attempts int, now time.Time) time.Time {
+	delay := p.Backoff
+	for i := 1; i < attempts && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return now.Add(min(delay, p.MaxBackoff))
+}
+
+// queryInsertEmail adalah query untuk memasukkan satu email ke antrean
+const queryInsertEmail = `INSERT INTO email_outbox (id, recipient, subject, text_body, html_body, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+// InsertEmail adalah fungsi untuk memasukkan email ke antrean pengiriman
+func InsertEmail(db *sql.DB, email Email) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	_, err := db.Exec(queryInsertEmail, email.ID, email.To, email.Subject, email.Text, nullableString(email.HTML), email.NextAttemptAt, email.CreatedAt)
+
+	return err
+}
+
+// insertEmail adalah fungsi untuk memasukkan email ke antrean di dalam transaction perubahan yang diberitahukan.
+// Email nil berarti tidak ada notifikasi.
+func insertEmail(tx *sql.Tx, email *Email) error {
+	if email == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(queryInsertEmail, email.ID, email.To, email.Subject, email.Text, nullableString(email.HTML), email.NextAttemptAt, email.CreatedAt)
+	return err
+}
+
+// ClaimPendingEmails adalah fungsi untuk mengambil email yang siap dikirim. Waktu percobaan berikutnya
+// langsung dimundurkan sebesar lease agar email yang sama tidak diambil oleh replika lain selama dikirim.
+func ClaimPendingEmails(db *sql.DB, now time.Time, limit int, maxAttempts int, lease time.Duration) ([]Email, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := `UPDATE email_outbox SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE sent_at IS NULL AND attempts < $2 AND next_attempt_at <= $3
+			ORDER BY next_attempt_at LIMIT $4 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, recipient, subject, text_body, COALESCE(html_body, ''), attempts, created_at`
+	rows, err := db.Query(query, now.Add(lease), maxAttempts, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ubah data hasil query ke bentuk slice
+	emails := []Email{}
+	for rows.Next() {
+		var email Email
+		if err := rows.Scan(&email.ID, &email.To, &email.Subject, &email.Text, &email.HTML, &email.Attempts, &email.CreatedAt); err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+
+	return emails, rows.Err()
+}
+
+// MarkEmailSent adalah fungsi untuk menandai email sudah terkirim
+func MarkEmailSent(db *sql.DB, id string, sentAt time.Time) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	_, err := db.Exec(`UPDATE email_outbox SET sent_at = $1, attempts = attempts + 1, last_error = NULL WHERE id = $2`, sentAt, id)
+	return err
+}
+
+// MarkEmailFailed adalah fungsi untuk mencatat pengiriman email yang gagal beserta waktu percobaan berikutnya
+func MarkEmailFailed(db *sql.DB, id string, lastError string, nextAttemptAt time.Time) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	_, err := db.Exec(`UPDATE email_outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3`, lastError, nextAttemptAt, id)
+	return err
+}
+
+// nullableString digunakan agar string kosong disimpan sebagai NULL
+func nullableString(value string) any {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
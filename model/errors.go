@@ -0,0 +1,88 @@
+package model
+
+import "maps"
+
+// ErrorKind adalah jenis error yang menentukan status HTTP
+type ErrorKind string
+
+const (
+	ErrorKindValidation      ErrorKind = "validation"        // 400
+	ErrorKindUnauthorized    ErrorKind = "unauthorized"      // 401
+	ErrorKindForbidden       ErrorKind = "forbidden"         // 403
+	ErrorKindNotFound        ErrorKind = "not_found"         // 404
+	ErrorKindConflict        ErrorKind = "conflict"          // 409
+	ErrorKindGone            ErrorKind = "gone"              // 410
+	ErrorKindUnprocessable   ErrorKind = "unprocessable"     // 422
+	ErrorKindTooManyRequests ErrorKind = "too_many_requests" // 429
+	ErrorKindInternal        ErrorKind = "internal"          // 500
+)
+
+// Error adalah error yang dapat ditampilkan ke client dengan kode yang stabil.
+// Client sebaiknya membaca Code, sedangkan Message dapat berubah.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []FieldError   // kesalahan per field untuk error validasi
+	Extra   map[string]any // data tambahan di response, misalnya produk yang stoknya tidak mencukupi
+}
+
+// NewError digunakan untuk membuat error baru dengan jenis, kode, dan pesan
+func NewError(kind ErrorKind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is digunakan agar errors.Is mengenali salinan error (misalnya hasil WithFields) sebagai error yang sama
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithFields digunakan untuk membuat salinan error beserta kesalahan per field
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	copied := *e
+	copied.Fields = append(append([]FieldError{}, e.Fields...), fields...)
+	return &copied
+}
+
+// With digunakan untuk membuat salinan error beserta data tambahan di response
+func (e *Error) With(key string, value any) *Error {
+	copied := *e
+	copied.Extra = maps.Clone(e.Extra)
+	if copied.Extra == nil {
+		copied.Extra = make(map[string]any)
+	}
+	copied.Extra[key] = value
+	return &copied
+}
+
+// error umum yang tidak terikat pada data tertentu
+var (
+	// ErrInternal adalah error ketika terjadi kesalahan yang tidak terduga di server
+	ErrInternal = NewError(ErrorKindInternal, "internal_error", "Terjadi kesalahan pada server")
+
+	// ErrRouteNotFound adalah error ketika endpoint yang diminta tidak ada
+	ErrRouteNotFound = NewError(ErrorKindNotFound, "route_not_found", "Endpoint tidak ditemukan")
+
+	// ErrInvalidRequest adalah error ketika request body atau parameter tidak valid
+	ErrInvalidRequest = NewError(ErrorKindValidation, "invalid_request", "Data request tidak valid")
+
+	// ErrUnauthorized adalah error ketika token tidak ada atau tidak valid
+	ErrUnauthorized = NewError(ErrorKindUnauthorized, "unauthorized", "Akses tidak diizinkan")
+
+	// ErrForbidden adalah error ketika pemanggil tidak memiliki akses ke endpoint
+	ErrForbidden = NewError(ErrorKindForbidden, "forbidden", "Peran admin tidak memiliki akses")
+
+	// ErrInvalidFilter adalah error ketika parameter query daftar data tidak valid
+	ErrInvalidFilter = NewError(ErrorKindValidation, "invalid_filter", "Parameter filter tidak valid")
+
+	// ErrInvalidLogin adalah error ketika data login admin atau pelanggan tidak valid
+	ErrInvalidLogin = NewError(ErrorKindValidation, "invalid_login", "Data login tidak valid")
+
+	// ErrInvalidCredentials adalah error ketika email atau password login salah
+	ErrInvalidCredentials = NewError(ErrorKindUnauthorized, "invalid_credentials", "Email atau password salah")
+)
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// IdempotencyKey adalah representasi dari Idempotency-Key yang tersimpan di database.
+// Kunci dari client tidak disimpan, hanya hash-nya. Response disimpan terenkripsi.
+type IdempotencyKey struct {
+	KeyHash     string
+	Fingerprint string // sidik jari request, request ulang dengan kunci yang sama harus identik
+	StatusCode  *int   // nil berarti request pertama masih diproses
+	ContentType string
+	Response    []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	LockedUntil time.Time // request yang masih diproses melewati batas ini dianggap macet, diperpanjang selama request berjalan
+}
+
+var (
+	// ErrInvalidIdempotencyKey adalah error ketika header Idempotency-Key tidak valid
+	ErrInvalidIdempotencyKey = NewError(ErrorKindValidation, "invalid_idempotency_key", "Idempotency-Key tidak valid")
+
+	// ErrUnreadableBody adalah error ketika request body tidak dapat dibaca
+	ErrUnreadableBody = NewError(ErrorKindValidation, "unreadable_body", "Request body tidak dapat dibaca")
+
+	// ErrIdempotencyKeyInProgress adalah error ketika request dengan kunci yang sama masih diproses
+	ErrIdempotencyKeyInProgress = NewError(ErrorKindConflict, "idempotency_key_in_progress", "Request dengan Idempotency-Key yang sama sedang diproses, ulangi beberapa saat lagi")
+
+	// ErrIdempotencyKeyReused adalah error ketika kunci yang sama dipakai untuk request yang berbeda
+	ErrIdempotencyKeyReused = NewError(ErrorKindUnprocessable, "idempotency_key_reused", "Idempotency-Key sudah dipakai untuk request yang berbeda")
+)
+
+// ReserveIdempotencyKey adalah fungsi untuk mencatat kunci yang mulai diproses.
+// Kunci yang sudah kedaluwarsa, atau masih diproses namun LockedUntil-nya sudah lewat (server berhenti di tengah request),
+// boleh dipakai ulang. Mengembalikan false jika kunci sudah dipakai request lain.
+func ReserveIdempotencyKey(db *sql.DB, key IdempotencyKey) (bool, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return false, errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := `INSERT INTO idempotency_keys (key_hash, fingerprint, created_at, expires_at, locked_until) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (key_hash) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, content_type = NULL,
+			response = NULL, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at, locked_until = EXCLUDED.locked_until
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= EXCLUDED.created_at)`
+	result, err := db.Exec(query, key.KeyHash, key.Fingerprint, key.CreatedAt, key.ExpiresAt, key.LockedUntil)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// SelectIdempotencyKey adalah fungsi untuk mengambil kunci berdasarkan hash-nya
+func SelectIdempotencyKey(db *sql.DB, keyHash string) (IdempotencyKey, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return IdempotencyKey{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	var key IdempotencyKey
+	var contentType sql.NullString
+	query := `SELECT key_hash, fingerprint, status_code, content_type, response, created_at, expires_at, locked_until FROM idempotency_keys WHERE key_hash = $1`
+	row := db.QueryRow(query, keyHash)
+	if err := row.Scan(&key.KeyHash, &key.Fingerprint, &key.StatusCode, &contentType, &key.Response, &key.CreatedAt, &key.ExpiresAt, &key.LockedUntil); err != nil {
+		return IdempotencyKey{}, err
+	}
+	key.ContentType = contentType.String
+
+	return key, nil
+}
+
+// ExtendIdempotencyKey adalah fungsi untuk memperpanjang batas waktu kunci yang masih diproses
+// agar request yang berjalan lama tidak dianggap macet
+func ExtendIdempotencyKey(db *sql.DB, keyHash string, fingerprint string, lockedUntil time.Time) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := `UPDATE idempotency_keys SET locked_until = $1 WHERE key_hash = $2 AND fingerprint = $3 AND status_code IS NULL`
+	if _, err := db.Exec(query, lockedUntil, keyHash, fingerprint); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CompleteIdempotencyKey adalah fungsi untuk menyimpan response dari request yang sudah selesai diproses
+func CompleteIdempotencyKey(db *sql.DB, keyHash string, fingerprint string, statusCode int, contentType string, response []byte) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := `UPDATE idempotency_keys SET status_code = $1, content_type = $2, response = $3
+		WHERE key_hash = $4 AND fingerprint = $5 AND status_code IS NULL`
+	if _, err := db.Exec(query, statusCode, contentType, response, keyHash, fingerprint); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey adalah fungsi untuk menghapus kunci yang belum selesai diproses
+// agar request dapat diulang dengan kunci yang sama
+func ReleaseIdempotencyKey(db *sql.DB, keyHash string, fingerprint string) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := `DELETE FROM idempotency_keys WHERE key_hash = $1 AND fingerprint = $2 AND status_code IS NULL`
+	if _, err := db.Exec(query, keyHash, fingerprint); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys adalah fungsi untuk menghapus kunci yang sudah kedaluwarsa.
+// Mengembalikan jumlah kunci yang dihapus.
+func DeleteExpiredIdempotencyKeys(db *sql.DB, now time.Time) (int64, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return 0, errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	result, err := db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
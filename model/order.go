@@ -3,20 +3,27 @@ package model
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
-// ProductQuantity adalah representasi dari data produk dan kuantitas di API
+// ProductQuantity adalah representasi dari data produk dan kuantitas di API.
+// Setiap baris divalidasi di handler agar error dapat menyebut baris yang salah.
 type ProductQuantity struct {
-	ID       string `json:"id" binding:"required"`
-	Quantity int32  `json:"quantity" binding:"required"`
+	ID        string `json:"id"`
+	VariantID string `json:"variantId"` // wajib diisi jika produk memiliki varian
+	Quantity  int32  `json:"quantity"`
 }
 
 // Checkout adalah representasi dari data checkout di API
 type Checkout struct {
-	Email    string            `json:"email" binding:"required,email"`
-	Address  string            `json:"address" binding:"required"`
-	Products []ProductQuantity `json:"products" binding:"min=1"` // minimal 1 produk
+	Email     string            `json:"email" binding:"required,email"`
+	Address   string            `json:"address" binding:"required"`
+	Products  []ProductQuantity `json:"products"` // wajib diisi jika tidak checkout dari keranjang
+	CartID    string            `json:"cartId"`   // checkout seluruh isi keranjang sebagai pengganti products
+	PromoCode string            `json:"promoCode"`
 }
 
 // Confirm adalah representasi dari data konfirmasi pembayaran di API
@@ -27,36 +34,211 @@ type Confirm struct {
 	Passcode      string `json:"passcode"`
 }
 
+// Cancel adalah representasi dari data pembatalan pesanan oleh pelanggan di API
+type Cancel struct {
+	Passcode string `json:"passcode"`
+	Reason   string `json:"reason" binding:"required,max=500"`
+}
+
+// RotatePasscode adalah representasi dari data penggantian passcode oleh pelanggan di API
+type RotatePasscode struct {
+	Passcode string `json:"passcode" binding:"required"`
+}
+
+var (
+	// ErrOrderNotFound adalah error ketika pesanan tidak ditemukan
+	ErrOrderNotFound = NewError(ErrorKindNotFound, "order_not_found", "Pesanan tidak ditemukan")
+
+	// ErrInvalidOrder adalah error ketika data pesanan pada request tidak valid
+	ErrInvalidOrder = NewError(ErrorKindValidation, "invalid_order", "Data pesanan tidak valid")
+
+	// ErrCartOrProducts adalah error ketika checkout mengisi cartId sekaligus products
+	ErrCartOrProducts = NewError(ErrorKindValidation, "cart_or_products", "Isi salah satu dari cartId atau products")
+
+	// ErrEmptyOrder adalah error ketika checkout tidak berisi produk
+	ErrEmptyOrder = NewError(ErrorKindValidation, "empty_order", "Produk pesanan tidak boleh kosong")
+
+	// ErrInsufficientStock adalah error ketika stok produk tidak mencukupi, lihat InsufficientStockError
+	ErrInsufficientStock = NewError(ErrorKindConflict, "insufficient_stock", "Stok produk tidak mencukupi")
+
+	// ErrInvalidConfirmation adalah error ketika data konfirmasi pembayaran tidak valid
+	ErrInvalidConfirmation = NewError(ErrorKindValidation, "invalid_confirmation", "Data konfirmasi tidak valid")
+
+	// ErrOrderAlreadyPaid adalah error ketika pesanan yang sudah dibayar dikonfirmasi lagi
+	ErrOrderAlreadyPaid = NewError(ErrorKindConflict, "order_already_paid", "Pesanan sudah dibayar")
+
+	// ErrOrderNotPayable adalah error ketika status pesanan tidak memungkinkan pembayaran
+	ErrOrderNotPayable = NewError(ErrorKindConflict, "order_not_payable", "Pesanan tidak dapat dibayar")
+
+	// ErrPaymentAmountMismatch adalah error ketika jumlah pembayaran tidak sama dengan total pesanan
+	ErrPaymentAmountMismatch = NewError(ErrorKindValidation, "payment_amount_mismatch", "Jumlah pembayaran tidak sesuai")
+
+	// ErrInvalidCancellation adalah error ketika data pembatalan pesanan tidak valid
+	ErrInvalidCancellation = NewError(ErrorKindValidation, "invalid_cancellation", "Data pembatalan tidak valid")
+
+	// ErrOrderNotCancellable adalah error ketika pesanan yang sudah dibayar dibatalkan oleh pelanggan
+	ErrOrderNotCancellable = NewError(ErrorKindConflict, "order_not_cancellable", "Hanya pesanan yang belum dibayar yang dapat dibatalkan")
+
+	// ErrInvalidPasscodeRotation adalah error ketika data penggantian passcode tidak valid
+	ErrInvalidPasscodeRotation = NewError(ErrorKindValidation, "invalid_passcode_rotation", "Data passcode tidak valid")
+
+	// ErrInvalidStatusChange adalah error ketika data perubahan status pesanan oleh admin tidak valid
+	ErrInvalidStatusChange = NewError(ErrorKindValidation, "invalid_status_change", "Data perubahan status tidak valid")
+)
+
 // Order adalah representasi dari data pesanan di database
 type Order struct {
-	ID                string     `json:"id"`
-	Email             string     `json:"email"`
-	Address           string     `json:"address"`
-	GrandTotal        int64      `json:"grandTotal"`
-	Passcode          *string    `json:"passcode,omitempty"`
-	PaidAt            *time.Time `json:"paidAt,omitempty"`
-	PaidBank          *string    `json:"paidBank,omitempty"`
-	PaidAccountNumber *string    `json:"paidAccountNumber,omitempty"`
+	ID                string      `json:"id"`
+	CustomerID        *string     `json:"customerId,omitempty"` // kosong untuk pesanan tamu
+	Email             string      `json:"email"`
+	Address           string      `json:"address"`
+	Subtotal          int64       `json:"subtotal"`
+	ShippingFee       int64       `json:"shippingFee"`
+	DiscountTotal     int64       `json:"discountTotal"`
+	GrandTotal        int64       `json:"grandTotal"`
+	Status            OrderStatus `json:"status"`
+	CreatedAt         time.Time   `json:"createdAt"`
+	ExpiresAt         *time.Time  `json:"expiresAt,omitempty"`
+	CancelledAt       *time.Time  `json:"cancelledAt,omitempty"`
+	CancelReason      *string     `json:"cancelReason,omitempty"`
+	Passcode          *string     `json:"passcode,omitempty"`
+	PaidAt            *time.Time  `json:"paidAt,omitempty"`
+	PaidBank          *string     `json:"paidBank,omitempty"`
+	PaidAccountNumber *string     `json:"paidAccountNumber,omitempty"`
+	Locale            string      `json:"locale,omitempty"` // bahasa email notifikasi, dipilih saat checkout
 }
 
 // OrderDetail adalah representasi dari detail data pesanan di database dan API
 type OrderDetail struct {
-	ID        string `json:"id"`
-	OrderID   string `json:"orderId"`
-	ProductID string `json:"productId"`
-	Quantity  int32  `json:"quantity"`
-	Price     int64  `json:"price"`
-	Total     int64  `json:"total"`
+	ID        string         `json:"id"`
+	OrderID   string         `json:"orderId"`
+	ProductID string         `json:"productId"`
+	VariantID *string        `json:"variantId,omitempty"`
+	SKU       *string        `json:"sku,omitempty"`
+	Options   VariantOptions `json:"options,omitempty"`
+	Quantity  int32          `json:"quantity"`
+	Price     int64          `json:"price"`
+	Total     int64          `json:"total"`
+}
+
+// OrderFilter adalah representasi dari parameter query untuk daftar pesanan pelanggan di API
+type OrderFilter struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// OrderList adalah representasi dari daftar pesanan beserta metadata halaman di API
+type OrderList struct {
+	Data []Order  `json:"data"`
+	Meta PageMeta `json:"meta"`
 }
 
 // OrderWithDetail adalah representasi dari data pesanan dengan detail untuk API (tidak menampilkan passcode)
 type OrderWithDetail struct {
 	Order
-	Detail []OrderDetail `json:"detail"`
+	Detail    []OrderDetail        `json:"detail"`
+	Discounts []OrderDiscount      `json:"discounts,omitempty"`
+	History   []OrderStatusHistory `json:"history,omitempty"`
+}
+
+// StockShortage adalah representasi dari produk atau varian yang stoknya tidak mencukupi untuk dipesan
+type StockShortage struct {
+	ProductID string  `json:"productId"`
+	VariantID *string `json:"variantId,omitempty"`
+	Requested int32   `json:"requested"`
+	Available int32   `json:"available"`
+}
+
+// InsufficientStockError adalah error ketika satu atau lebih produk tidak memiliki stok yang cukup
+type InsufficientStockError struct {
+	Shortages []StockShortage
+}
+
+func (e *InsufficientStockError) Error() string {
+	ids := make([]string, len(e.Shortages))
+	for i, s := range e.Shortages {
+		ids[i] = s.ProductID
+		if s.VariantID != nil {
+			ids[i] += "/" + *s.VariantID
+		}
+	}
+
+	return fmt.Sprintf("stok tidak mencukupi untuk produk: %s", strings.Join(ids, ", "))
+}
+
+// Unwrap digunakan agar error dapat ditampilkan sebagai ErrInsufficientStock beserta daftar produknya
+func (e *InsufficientStockError) Unwrap() error {
+	return ErrInsufficientStock.With("products", e.Shortages)
+}
+
+// StockRequest adalah jumlah stok yang diminta sebuah pesanan, dijumlahkan per produk (tanpa varian) dan per varian
+type StockRequest struct {
+	Products       map[string]int32  // kuantitas per ID produk yang dipesan tanpa varian
+	Variants       map[string]int32  // kuantitas per ID varian
+	VariantProduct map[string]string // ID produk dari setiap varian
+}
+
+// NewStockRequest digunakan untuk menjumlahkan kuantitas detail pesanan per produk dan per varian
+func NewStockRequest(details []OrderDetail) StockRequest {
+	request := StockRequest{
+		Products:       make(map[string]int32),
+		Variants:       make(map[string]int32),
+		VariantProduct: make(map[string]string),
+	}
+
+	for _, detail := range details {
+		if detail.VariantID != nil {
+			request.Variants[*detail.VariantID] += detail.Quantity
+			request.VariantProduct[*detail.VariantID] = detail.ProductID
+			continue
+		}
+
+		request.Products[detail.ProductID] += detail.Quantity
+	}
+
+	return request
+}
+
+// Check digunakan untuk membandingkan permintaan dengan stok yang tersedia (produk atau varian yang tidak ada
+// di map dianggap tidak memiliki stok). Hasilnya *InsufficientStockError berisi seluruh kekurangan, urut per ID,
+// atau nil jika stok mencukupi.
+func (r StockRequest) Check(productStock map[string]int32, variantStock map[string]int32) error {
+	shortages := []StockShortage{}
+	for _, id := range sortedIDs(r.Products) {
+		if productStock[id] < r.Products[id] {
+			shortages = append(shortages, StockShortage{ProductID: id, Requested: r.Products[id], Available: productStock[id]})
+		}
+	}
+
+	for _, id := range sortedIDs(r.Variants) {
+		if variantStock[id] < r.Variants[id] {
+			variantID := id
+			shortages = append(shortages, StockShortage{ProductID: r.VariantProduct[id], VariantID: &variantID, Requested: r.Variants[id], Available: variantStock[id]})
+		}
+	}
+
+	if len(shortages) > 0 {
+		return &InsufficientStockError{Shortages: shortages}
+	}
+
+	return nil
 }
 
-// CreateOrder adalah fungsi untuk menyimpan data pesanan ke database
-func CreateOrder(db *sql.DB, order Order, details []OrderDetail) error {
+// sortedIDs digunakan untuk mengambil ID dari map kuantitas secara berurutan
+func sortedIDs(quantities map[string]int32) []string {
+	ids := make([]string, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// CreateOrder adalah fungsi untuk menyimpan data pesanan ke database.
+// Jika pesanan dibuat dari keranjang (cart tidak nil), item keranjang dihapus di transaction yang sama.
+// Email notifikasi (jika tidak nil) dimasukkan ke antrean di transaction yang sama.
+func CreateOrder(db *sql.DB, order Order, details []OrderDetail, discounts []OrderDiscount, cart *CartCheckout, email *Email) error {
 	// pastikan koneksi ke database tidak nil
 	if db == nil {
 		return errors.New("tidak ada koneksi ke database")
@@ -68,24 +250,73 @@ func CreateOrder(db *sql.DB, order Order, details []OrderDetail) error {
 		return err
 	}
 
+	// kunci keranjang dan hapus item yang di-checkout
+	if cart != nil {
+		if err := checkoutCart(tx, *cart, order.CreatedAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// kurangi stok produk sesuai jumlah pesanan
+	if err := reserveStock(tx, details); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// query untuk simpan data order
-	queryOrder := `INSERT INTO orders (id, email, address, passcode, grand_total) VALUES ($1, $2, $3, $4, $5)`
-	_, err = tx.Exec(queryOrder, order.ID, order.Email, order.Address, order.Passcode, order.GrandTotal)
+	queryOrder := `INSERT INTO orders (id, customer_id, email, address, passcode, subtotal, shipping_fee, discount_total, grand_total, status, created_at, expires_at, locale)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+	_, err = tx.Exec(queryOrder, order.ID, order.CustomerID, order.Email, order.Address, order.Passcode, order.Subtotal, order.ShippingFee, order.DiscountTotal,
+		order.GrandTotal, OrderStatusPending, order.CreatedAt, order.ExpiresAt, nullableString(order.Locale))
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
+	// catat status awal pesanan
+	if err := insertStatusHistory(tx, NewStatusHistory(order.ID, nil, OrderStatusPending, "", order.CreatedAt)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// query untuk simpan data detail order
-	queryDetail := `INSERT INTO order_details (id, order_id, product_id, quantity, price, total) VALUES ($1, $2, $3, $4, $5, $6)`
+	queryDetail := `INSERT INTO order_details (id, order_id, product_id, variant_id, sku, variant_options, quantity, price, total) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 	for _, detail := range details {
-		_, err = tx.Exec(queryDetail, detail.ID, detail.OrderID, detail.ProductID, detail.Quantity, detail.Price, detail.Total)
+		// simpan opsi varian hanya untuk detail yang memiliki varian
+		var options any
+		if detail.VariantID != nil {
+			options = detail.Options
+		}
+
+		_, err = tx.Exec(queryDetail, detail.ID, detail.OrderID, detail.ProductID, detail.VariantID, detail.SKU, options, detail.Quantity, detail.Price, detail.Total)
 		if err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
 
+	// periksa ulang batas pemakaian promo lalu simpan data potongan
+	queryDiscount := `INSERT INTO order_discounts (id, order_id, promo_code_id, code, type, amount) VALUES ($1, $2, $3, $4, $5, $6)`
+	for _, discount := range discounts {
+		if err := lockPromoUsage(tx, discount.PromoCodeID, order.Email); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		_, err = tx.Exec(queryDiscount, discount.ID, discount.OrderID, discount.PromoCodeID, discount.Code, discount.Type, discount.Amount)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// masukkan email pesanan dibuat ke antrean
+	if err := insertEmail(tx, email); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// commit transaction
 	err = tx.Commit()
 	if err != nil {
@@ -96,20 +327,156 @@ func CreateOrder(db *sql.DB, order Order, details []OrderDetail) error {
 	return nil
 }
 
+// reserveStock adalah fungsi untuk mengunci baris produk/varian dan mengurangi stoknya di dalam transaction
+func reserveStock(tx *sql.Tx, details []OrderDetail) error {
+	request := NewStockRequest(details)
+
+	// kunci dan ambil stok produk
+	productStock, err := lockStock(tx, "products", sortedIDs(request.Products))
+	if err != nil {
+		return err
+	}
+
+	// kunci dan ambil stok varian
+	variantStock, err := lockStock(tx, "product_variants", sortedIDs(request.Variants))
+	if err != nil {
+		return err
+	}
+
+	// pastikan stok seluruh produk dan varian mencukupi
+	if err := request.Check(productStock, variantStock); err != nil {
+		return err
+	}
+
+	// kurangi stok produk dan varian
+	for id, quantity := range request.Products {
+		if _, err := tx.Exec(`UPDATE products SET stock = stock - $1 WHERE id = $2`, quantity, id); err != nil {
+			return err
+		}
+	}
+
+	for id, quantity := range request.Variants {
+		if _, err := tx.Exec(`UPDATE product_variants SET stock = stock - $1 WHERE id = $2`, quantity, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockStock adalah fungsi untuk mengunci baris pada tabel berstok sesuai urutan ids lalu mengambil stoknya
+func lockStock(tx *sql.Tx, table string, ids []string) (map[string]int32, error) {
+	// tidak perlu query jika tidak ada yang dipesan
+	available := make(map[string]int32)
+	if len(ids) == 0 {
+		return available, nil
+	}
+
+	// buat placeholder & args untuk query
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	// kunci baris yang dipesan, diurutkan agar tidak terjadi deadlock
+	query := fmt.Sprintf(`SELECT id, stock FROM %s WHERE id IN (%s) ORDER BY id FOR UPDATE`, table, strings.Join(placeholders, ","))
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ambil stok yang tersedia
+	for rows.Next() {
+		var id string
+		var stock int32
+		if err := rows.Scan(&id, &stock); err != nil {
+			return nil, err
+		}
+
+		available[id] = stock
+	}
+
+	return available, rows.Err()
+}
+
 // UpdateOrderStatus adalah fungsi untuk mengubah status pesanan menjadi sudah dibayar
-func UpdateOrderStatus(db *sql.DB, id string, confirmation Confirm, paidAt time.Time) error {
+// beserta email notifikasinya (jika tidak nil)
+func UpdateOrderStatus(db *sql.DB, id string, confirmation Confirm, paidAt time.Time, email *Email) error {
 	// pastikan koneksi ke database tidak nil
 	if db == nil {
 		return errors.New("tidak ada koneksi ke database")
 	}
 
-	// query untuk update status pesanan
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// kunci pesanan dan pastikan belum melewati batas waktu pembayaran
+	var expiresAt *time.Time
+	if err := tx.QueryRow(`SELECT expires_at FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&expiresAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if expiresAt != nil && paidAt.After(*expiresAt) {
+		tx.Rollback()
+		return ErrOrderExpired
+	}
+
+	// pindahkan status pesanan menjadi sudah dibayar
+	if err := transitionOrder(tx, id, OrderStatusPaid, "", paidAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// query untuk update data pembayaran pesanan
 	query := `UPDATE orders SET paid_at = $1, paid_bank = $2, paid_account_number = $3 WHERE id = $4`
-	_, err := db.Exec(query, paidAt, confirmation.Bank, confirmation.AccountNumber, id)
+	_, err = tx.Exec(query, paidAt, confirmation.Bank, confirmation.AccountNumber, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// masukkan email pembayaran diterima ke antrean
+	if err := insertEmail(tx, email); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// ErrPasscodeChanged adalah error ketika passcode pesanan sudah diganti oleh permintaan lain
+var ErrPasscodeChanged = NewError(ErrorKindConflict, "passcode_changed", "Passcode pesanan sudah diganti")
+
+// UpdateOrderPasscode adalah fungsi untuk mengganti hash passcode pesanan, hanya jika hash saat ini masih sama
+func UpdateOrderPasscode(db *sql.DB, id string, currentHash string, newHash string) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	result, err := db.Exec(`UPDATE orders SET passcode = $1 WHERE id = $2 AND passcode = $3`, newHash, id, currentHash)
 	if err != nil {
 		return err
 	}
 
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrPasscodeChanged
+	}
+
 	return nil
 }
 
@@ -121,14 +488,14 @@ func SelectOrderByID(db *sql.DB, id string) (Order, error) {
 	}
 
 	// query untuk mengambil data order
-	queryOrder := `SELECT id, email, address, passcode, grand_total, paid_at, paid_bank, paid_account_number FROM orders WHERE id = $1`
+	queryOrder := `SELECT id, customer_id, email, address, passcode, subtotal, shipping_fee, discount_total, grand_total, status, created_at, expires_at, cancelled_at, cancel_reason, paid_at, paid_bank, paid_account_number, COALESCE(locale, '') FROM orders WHERE id = $1`
 	row := db.QueryRow(queryOrder, id)
 
 	// siapkah variabel untuk menampung data order
 	order := Order{}
 
 	// ambil data dari row
-	err := row.Scan(&order.ID, &order.Email, &order.Address, &order.Passcode, &order.GrandTotal, &order.PaidAt, &order.PaidBank, &order.PaidAccountNumber)
+	err := row.Scan(&order.ID, &order.CustomerID, &order.Email, &order.Address, &order.Passcode, &order.Subtotal, &order.ShippingFee, &order.DiscountTotal, &order.GrandTotal, &order.Status, &order.CreatedAt, &order.ExpiresAt, &order.CancelledAt, &order.CancelReason, &order.PaidAt, &order.PaidBank, &order.PaidAccountNumber, &order.Locale)
 	if err != nil {
 		return Order{}, err
 	}
@@ -136,6 +503,68 @@ func SelectOrderByID(db *sql.DB, id string) (Order, error) {
 	return order, nil
 }
 
+// SelectOrderByCustomerID adalah fungsi untuk mengambil data pesanan milik pelanggan, terbaru lebih dulu (tanpa passcode)
+func SelectOrderByCustomerID(db *sql.DB, customerID string, page int, limit int) ([]Order, int, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, 0, errors.New("tidak ada koneksi ke database")
+	}
+
+	// hitung total pesanan pelanggan
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM orders WHERE customer_id = $1`, customerID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// query untuk mengambil data order
+	query := `SELECT id, customer_id, email, address, subtotal, shipping_fee, discount_total, grand_total, status, created_at, expires_at, cancelled_at, cancel_reason, paid_at, paid_bank, paid_account_number, COALESCE(locale, '')
+		FROM orders WHERE customer_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`
+	rows, err := db.Query(query, customerID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// ubah data hasil query ke bentuk slice
+	orders := []Order{}
+	for rows.Next() {
+		var order Order
+		err := rows.Scan(&order.ID, &order.CustomerID, &order.Email, &order.Address, &order.Subtotal, &order.ShippingFee, &order.DiscountTotal, &order.GrandTotal, &order.Status, &order.CreatedAt, &order.ExpiresAt, &order.CancelledAt, &order.CancelReason, &order.PaidAt, &order.PaidBank, &order.PaidAccountNumber, &order.Locale)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, total, rows.Err()
+}
+
+// ErrOrderAlreadyAttached adalah error ketika pesanan sudah ditautkan ke akun pelanggan lain
+var ErrOrderAlreadyAttached = NewError(ErrorKindConflict, "order_already_attached", "Pesanan sudah ditautkan ke akun lain")
+
+// AttachOrderToCustomer adalah fungsi untuk menautkan pesanan tamu ke akun pelanggan.
+// Pesanan yang sudah milik pelanggan lain tidak dapat ditautkan.
+func AttachOrderToCustomer(db *sql.DB, orderID string, customerID string) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query, pesanan yang sudah milik pelanggan yang sama dianggap berhasil
+	query := `UPDATE orders SET customer_id = $1 WHERE id = $2 AND (customer_id IS NULL OR customer_id = $1)`
+	result, err := db.Exec(query, customerID, orderID)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrOrderAlreadyAttached
+	}
+
+	return nil
+}
+
 // SelectOrderDetailByOrderID adalah fungsi untuk mengambil data detail pesanan berdasarkan ID pesanan
 func SelectOrderDetailByOrderID(db *sql.DB, orderID string) ([]OrderDetail, error) {
 	// pastikan koneksi ke database tidak nil
@@ -144,7 +573,7 @@ func SelectOrderDetailByOrderID(db *sql.DB, orderID string) ([]OrderDetail, erro
 	}
 
 	// query untuk mengambil data detail order
-	queryDetail := `SELECT id, order_id, product_id, quantity, price, total FROM order_details WHERE order_id = $1`
+	queryDetail := `SELECT id, order_id, product_id, variant_id, sku, variant_options, quantity, price, total FROM order_details WHERE order_id = $1`
 	rows, err := db.Query(queryDetail, orderID)
 	if err != nil {
 		return nil, err
@@ -157,7 +586,7 @@ func SelectOrderDetailByOrderID(db *sql.DB, orderID string) ([]OrderDetail, erro
 	// ambil data dari rows
 	for rows.Next() {
 		detail := OrderDetail{}
-		err := rows.Scan(&detail.ID, &detail.OrderID, &detail.ProductID, &detail.Quantity, &detail.Price, &detail.Total)
+		err := rows.Scan(&detail.ID, &detail.OrderID, &detail.ProductID, &detail.VariantID, &detail.SKU, &detail.Options, &detail.Quantity, &detail.Price, &detail.Total)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,334 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderStatus adalah status dari sebuah pesanan
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusExpired   OrderStatus = "expired"
+)
+
+// orderTransitions berisi daftar perpindahan status pesanan yang diizinkan
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending: {OrderStatusPaid, OrderStatusCancelled, OrderStatusExpired},
+	OrderStatusPaid:    {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped: {OrderStatusDelivered},
+}
+
+// ErrInvalidStatusTransition adalah error ketika perpindahan status pesanan tidak diizinkan
+var ErrInvalidStatusTransition = NewError(ErrorKindConflict, "invalid_status_transition", "Status pesanan tidak dapat diubah")
+
+// ErrOrderExpired adalah error ketika pesanan sudah melewati batas waktu pembayaran
+var ErrOrderExpired = NewError(ErrorKindGone, "order_expired", "Pesanan sudah kedaluwarsa")
+
+// CanTransitionTo digunakan untuk memeriksa apakah status dapat berpindah ke status tujuan
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Transition digunakan untuk memvalidasi perpindahan status, menghasilkan ErrInvalidStatusTransition jika tidak diizinkan
+func (s OrderStatus) Transition(next OrderStatus) error {
+	if !s.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, s, next)
+	}
+
+	return nil
+}
+
+// CustomerCancel digunakan untuk memvalidasi pembatalan oleh pelanggan, yang hanya boleh untuk pesanan belum dibayar
+func (s OrderStatus) CustomerCancel() error {
+	if s != OrderStatusPending {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, s, OrderStatusCancelled)
+	}
+
+	return nil
+}
+
+// IsVoid digunakan untuk memeriksa apakah pesanan batal (dibatalkan atau kedaluwarsa). Stok pesanan batal
+// dikembalikan dan potongannya tidak dihitung sebagai pemakaian kode promo.
+func (s OrderStatus) IsVoid() bool {
+	return s == OrderStatusCancelled || s == OrderStatusExpired
+}
+
+// NewStatusHistory digunakan untuk membuat riwayat perpindahan status pesanan, catatan kosong tidak disimpan
+func NewStatusHistory(orderID string, from *OrderStatus, to OrderStatus, note string, changedAt time.Time) OrderStatusHistory {
+	history := OrderStatusHistory{
+		ID:         uuid.New().String(),
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedAt:  changedAt,
+	}
+	if note != "" {
+		history.Note = &note
+	}
+
+	return history
+}
+
+// StatusChange adalah representasi dari data perubahan status pesanan oleh admin di API
+type StatusChange struct {
+	Note string `json:"note"`
+}
+
+// OrderStatusHistory adalah representasi dari riwayat perubahan status pesanan di database dan API
+type OrderStatusHistory struct {
+	ID         string       `json:"id"`
+	OrderID    string       `json:"orderId"`
+	FromStatus *OrderStatus `json:"fromStatus,omitempty"`
+	ToStatus   OrderStatus  `json:"toStatus"`
+	Note       *string      `json:"note,omitempty"`
+	ChangedAt  time.Time    `json:"changedAt"`
+}
+
+// ChangeOrderStatus adalah fungsi untuk memindahkan status pesanan sesuai state machine dan menyimpan audit log-nya
+// beserta email notifikasinya (jika tidak nil)
+func ChangeOrderStatus(db *sql.DB, id string, to OrderStatus, note string, changedAt time.Time, audit *AuditLog, email *Email) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// pindahkan status pesanan
+	if err := transitionOrder(tx, id, to, note, changedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// simpan audit log di transaction yang sama
+	if err := insertAuditLog(tx, audit); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// masukkan email perubahan status ke antrean
+	if err := insertEmail(tx, email); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// CancelUnpaidOrder adalah fungsi untuk membatalkan pesanan yang belum dibayar oleh pelanggan
+// beserta email notifikasinya (jika tidak nil)
+func CancelUnpaidOrder(db *sql.DB, id string, reason string, cancelledAt time.Time, email *Email) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// kunci pesanan dan pastikan belum dibayar
+	var status OrderStatus
+	if err := tx.QueryRow(`SELECT status FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&status); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := status.CustomerCancel(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// batalkan pesanan
+	if err := transitionOrder(tx, id, OrderStatusCancelled, reason, cancelledAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// masukkan email pesanan dibatalkan ke antrean
+	if err := insertEmail(tx, email); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// transitionOrder adalah fungsi untuk mengunci pesanan, memvalidasi dan menyimpan perpindahan status di dalam transaction
+func transitionOrder(tx *sql.Tx, id string, to OrderStatus, note string, changedAt time.Time) error {
+	// kunci baris pesanan dan ambil status saat ini
+	var from OrderStatus
+	err := tx.QueryRow(`SELECT status FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&from)
+	if err != nil {
+		return err
+	}
+
+	// pastikan perpindahan status diizinkan
+	if err := from.Transition(to); err != nil {
+		return err
+	}
+
+	// update status pesanan
+	if _, err := tx.Exec(`UPDATE orders SET status = $1 WHERE id = $2`, to, id); err != nil {
+		return err
+	}
+
+	// catat waktu dan alasan pembatalan
+	if to == OrderStatusCancelled {
+		if _, err := tx.Exec(`UPDATE orders SET cancelled_at = $1, cancel_reason = NULLIF($2, '') WHERE id = $3`, changedAt, note, id); err != nil {
+			return err
+		}
+	}
+
+	// kembalikan stok produk jika pesanan dibatalkan atau kedaluwarsa
+	if to.IsVoid() {
+		if err := releaseStock(tx, id); err != nil {
+			return err
+		}
+	}
+
+	// simpan riwayat perubahan status
+	return insertStatusHistory(tx, NewStatusHistory(id, &from, to, note, changedAt))
+}
+
+// insertStatusHistory adalah fungsi untuk menyimpan riwayat perubahan status pesanan
+func insertStatusHistory(tx *sql.Tx, history OrderStatusHistory) error {
+	query := `INSERT INTO order_status_histories (id, order_id, from_status, to_status, note, changed_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := tx.Exec(query, history.ID, history.OrderID, history.FromStatus, history.ToStatus, history.Note, history.ChangedAt)
+	return err
+}
+
+// releaseStock adalah fungsi untuk mengembalikan stok produk dan varian dari detail pesanan
+func releaseStock(tx *sql.Tx, orderID string) error {
+	queryProduct := `
+	UPDATE products p SET stock = p.stock + d.quantity
+	FROM (SELECT product_id, SUM(quantity) AS quantity FROM order_details WHERE order_id = $1 AND variant_id IS NULL GROUP BY product_id) d
+	WHERE p.id = d.product_id`
+
+	if _, err := tx.Exec(queryProduct, orderID); err != nil {
+		return err
+	}
+
+	queryVariant := `
+	UPDATE product_variants v SET stock = v.stock + d.quantity
+	FROM (SELECT variant_id, SUM(quantity) AS quantity FROM order_details WHERE order_id = $1 AND variant_id IS NOT NULL GROUP BY variant_id) d
+	WHERE v.id = d.variant_id`
+
+	_, err := tx.Exec(queryVariant, orderID)
+	return err
+}
+
+// SelectOrderStatusHistory adalah fungsi untuk mengambil riwayat status pesanan berdasarkan ID pesanan
+func SelectOrderStatusHistory(db *sql.DB, orderID string) ([]OrderStatusHistory, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk mengambil riwayat status pesanan
+	query := `SELECT id, order_id, from_status, to_status, note, changed_at FROM order_status_histories WHERE order_id = $1 ORDER BY changed_at, id`
+	rows, err := db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ambil data dari rows
+	histories := []OrderStatusHistory{}
+	for rows.Next() {
+		history := OrderStatusHistory{}
+		err := rows.Scan(&history.ID, &history.OrderID, &history.FromStatus, &history.ToStatus, &history.Note, &history.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		histories = append(histories, history)
+	}
+
+	return histories, nil
+}
+
+// ExpireOverdueOrders adalah fungsi untuk menandai pesanan yang belum dibayar dan melewati batas waktu sebagai kedaluwarsa
+func ExpireOverdueOrders(db *sql.DB, now time.Time) (int, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return 0, errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	// ambil pesanan yang kedaluwarsa, lewati pesanan yang sedang dikunci proses lain
+	query := `SELECT id FROM orders WHERE status = $1 AND expires_at IS NOT NULL AND expires_at < $2 ORDER BY expires_at LIMIT 100 FOR UPDATE SKIP LOCKED`
+	rows, err := tx.Query(query, OrderStatusPending, now)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return 0, err
+		}
+
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	// pindahkan status setiap pesanan menjadi kedaluwarsa
+	for _, id := range ids {
+		if err := transitionOrder(tx, id, OrderStatusExpired, "batas waktu pembayaran terlewati", now); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return len(ids), nil
+}
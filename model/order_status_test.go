@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrderStatusTransition(t *testing.T) {
+	for _, tc := range []struct {
+		from, to OrderStatus
+		allowed  bool
+	}{
+		{OrderStatusPending, OrderStatusPaid, true},
+		{OrderStatusPending, OrderStatusExpired, true},
+		{OrderStatusPaid, OrderStatusCancelled, true},
+		{OrderStatusShipped, OrderStatusDelivered, true},
+		{OrderStatusPending, OrderStatusShipped, false},
+		{OrderStatusShipped, OrderStatusCancelled, false},
+		{OrderStatusCancelled, OrderStatusPaid, false},
+		{OrderStatusExpired, OrderStatusPaid, false},
+	} {
+		err := tc.from.Transition(tc.to)
+		if tc.allowed && err != nil {
+			t.Errorf("%s -> %s: %v, seharusnya diizinkan", tc.from, tc.to, err)
+		}
+		if !tc.allowed && !errors.Is(err, ErrInvalidStatusTransition) {
+			t.Errorf("%s -> %s: %v, seharusnya ErrInvalidStatusTransition", tc.from, tc.to, err)
+		}
+	}
+}
+
+func TestOrderStatusCustomerCancel(t *testing.T) {
+	if err := OrderStatusPending.CustomerCancel(); err != nil {
+		t.Errorf("pesanan belum dibayar: %v, seharusnya dapat dibatalkan", err)
+	}
+
+	// pesanan yang sudah dibayar hanya dapat dibatalkan oleh admin
+	if err := OrderStatusPaid.CustomerCancel(); !errors.Is(err, ErrInvalidStatusTransition) {
+		t.Errorf("pesanan dibayar: %v, seharusnya ErrInvalidStatusTransition", err)
+	}
+}
+
+func TestOrderStatusIsVoid(t *testing.T) {
+	for status, want := range map[OrderStatus]bool{
+		OrderStatusPending:   false,
+		OrderStatusPaid:      false,
+		OrderStatusShipped:   false,
+		OrderStatusDelivered: false,
+		OrderStatusCancelled: true,
+		OrderStatusExpired:   true,
+	} {
+		if got := status.IsVoid(); got != want {
+			t.Errorf("%s: IsVoid = %v, seharusnya %v", status, got, want)
+		}
+	}
+}
+
+func TestNewStatusHistory(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	from := OrderStatusPending
+
+	history := NewStatusHistory("order-1", &from, OrderStatusCancelled, "Salah ukuran", now)
+	if history.ID == "" || history.OrderID != "order-1" || *history.FromStatus != from || history.ToStatus != OrderStatusCancelled {
+		t.Errorf("riwayat = %+v", history)
+	}
+	if history.Note == nil || *history.Note != "Salah ukuran" {
+		t.Errorf("catatan = %v, seharusnya Salah ukuran", history.Note)
+	}
+
+	// catatan kosong tidak disimpan
+	if history := NewStatusHistory("order-1", nil, OrderStatusPending, "", now); history.Note != nil || history.FromStatus != nil {
+		t.Errorf("riwayat awal = %+v, seharusnya tanpa status asal dan catatan", history)
+	}
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStockRequestCheck(t *testing.T) {
+	v1, v2 := "v1", "v2"
+	request := NewStockRequest([]OrderDetail{
+		{ProductID: "p2", Quantity: 2},
+		{ProductID: "p1", Quantity: 1},
+		{ProductID: "p1", Quantity: 2},
+		{ProductID: "p3", VariantID: &v1, Quantity: 1},
+		{ProductID: "p3", VariantID: &v2, Quantity: 4},
+	})
+
+	// baris dengan produk yang sama dijumlahkan, varian dihitung terpisah dari produknya
+	if request.Products["p1"] != 3 || request.Products["p2"] != 2 || len(request.Products) != 2 {
+		t.Errorf("products = %v, seharusnya p1: 3, p2: 2", request.Products)
+	}
+	if request.Variants["v1"] != 1 || request.Variants["v2"] != 4 || request.VariantProduct["v2"] != "p3" {
+		t.Errorf("variants = %v (%v), seharusnya v1: 1, v2: 4 milik p3", request.Variants, request.VariantProduct)
+	}
+
+	if err := request.Check(map[string]int32{"p1": 3, "p2": 5}, map[string]int32{"v1": 1, "v2": 4}); err != nil {
+		t.Errorf("stok cukup: %v", err)
+	}
+
+	// produk yang tidak ada dianggap tidak memiliki stok, kekurangan diurutkan per ID
+	err := request.Check(map[string]int32{"p2": 1}, map[string]int32{"v1": 1, "v2": 3})
+	var stockErr *InsufficientStockError
+	if !errors.As(err, &stockErr) {
+		t.Fatalf("err = %v, seharusnya InsufficientStockError", err)
+	}
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("err = %v, seharusnya dapat dicocokkan dengan ErrInsufficientStock", err)
+	}
+
+	shortages := stockErr.Shortages
+	if len(shortages) != 3 {
+		t.Fatalf("kekurangan = %+v, seharusnya 3", shortages)
+	}
+	if shortages[0].ProductID != "p1" || shortages[0].Available != 0 || shortages[1].ProductID != "p2" || shortages[1].Available != 1 {
+		t.Errorf("kekurangan produk = %+v", shortages[:2])
+	}
+	if shortages[2].ProductID != "p3" || shortages[2].VariantID == nil || *shortages[2].VariantID != "v2" || shortages[2].Requested != 4 || shortages[2].Available != 3 {
+		t.Errorf("kekurangan varian = %+v", shortages[2])
+	}
+}
@@ -0,0 +1,240 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// AttemptScope adalah jenis penghitung percobaan passcode yang gagal
+type AttemptScope string
+
+const (
+	AttemptScopeOrder AttemptScope = "order" // per ID pesanan
+	AttemptScopeIP    AttemptScope = "ip"    // per alamat IP client
+)
+
+// AttemptKey adalah identitas penghitung percobaan passcode
+type AttemptKey struct {
+	Scope AttemptScope
+	Key   string
+}
+
+// PasscodeAttempt adalah keadaan penghitung percobaan passcode yang gagal
+type PasscodeAttempt struct {
+	Failures     int
+	LastFailedAt *time.Time
+	LockedUntil  *time.Time
+}
+
+var (
+	// ErrInvalidPasscode adalah error ketika passcode pesanan salah
+	ErrInvalidPasscode = NewError(ErrorKindUnauthorized, "invalid_passcode", "Passcode tidak valid")
+
+	// ErrTooManyPasscodeAttempts adalah error ketika pesanan atau IP sedang dikunci karena passcode salah
+	ErrTooManyPasscodeAttempts = NewError(ErrorKindTooManyRequests, "too_many_passcode_attempts", "Terlalu banyak percobaan passcode, coba lagi nanti")
+)
+
+// PasscodePolicy adalah aturan pembatasan percobaan passcode yang gagal
+type PasscodePolicy struct {
+	MaxOrderAttempts int           // jumlah gagal per pesanan sebelum dikunci
+	MaxIPAttempts    int           // jumlah gagal per IP sebelum dikunci
+	BaseBackoff      time.Duration // jeda setelah gagal pertama, berlipat dua setiap kegagalan berikutnya
+	Lockout          time.Duration // lama penguncian setelah batas gagal tercapai
+}
+
+// maxAttempts digunakan untuk mendapatkan batas gagal sesuai jenis penghitung
+func (p PasscodePolicy) maxAttempts(scope AttemptScope) int {
+	if scope == AttemptScopeIP {
+		return p.MaxIPAttempts
+	}
+
+	return p.MaxOrderAttempts
+}
+
+// Fail digunakan untuk menghitung keadaan penghitung setelah satu percobaan gagal.
+// Penghitung dimulai ulang jika kegagalan terakhir sudah lebih lama dari masa penguncian.
+func (p PasscodePolicy) Fail(scope AttemptScope, attempt PasscodeAttempt, now time.Time) PasscodeAttempt {
+	if attempt.LastFailedAt != nil && now.Sub(*attempt.LastFailedAt) > p.Lockout &&
+		(attempt.LockedUntil == nil || !now.Before(*attempt.LockedUntil)) {
+		attempt.Failures = 0
+	}
+
+	attempt.Failures++
+	attempt.LastFailedAt = &now
+
+	// kunci penuh setelah batas gagal tercapai, selain itu jeda bertambah secara eksponensial
+	lock := p.Lockout
+	if attempt.Failures < p.maxAttempts(scope) {
+		lock = p.BaseBackoff
+		for i := 1; i < attempt.Failures && lock < p.Lockout; i++ {
+			lock *= 2
+		}
+		lock = min(lock, p.Lockout)
+	}
+
+	lockedUntil := now.Add(lock)
+	attempt.LockedUntil = &lockedUntil
+
+	return attempt
+}
+
+// PasscodeReservation adalah percobaan passcode yang sudah dicatat sebagai gagal sebelum passcode dicocokkan,
+// sehingga percobaan yang berjalan bersamaan tidak dapat melewati jeda maupun penguncian
+type PasscodeReservation struct {
+	LockedUntil time.Time         // tidak nol jika salah satu penghitung sedang dikunci, percobaan tidak dicatat
+	Keys        []AttemptKey      // penghitung yang dicatat
+	Previous    []PasscodeAttempt // keadaan penghitung sebelum percobaan dicatat
+	Reserved    []PasscodeAttempt // keadaan penghitung setelah percobaan dicatat
+}
+
+// Reserve digunakan untuk mencatat satu percobaan sebagai gagal pada setiap penghitung sebelum passcode dicocokkan.
+// attempts adalah keadaan penghitung saat ini dengan urutan yang sama dengan keys. Jika salah satu penghitung
+// sedang dikunci, tidak ada yang dicatat dan LockedUntil diisi dengan batas penguncian terlama.
+func (p PasscodePolicy) Reserve(keys []AttemptKey, attempts []PasscodeAttempt, now time.Time) PasscodeReservation {
+	reservation := PasscodeReservation{Keys: keys, Previous: attempts}
+	for _, attempt := range attempts {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) && attempt.LockedUntil.After(reservation.LockedUntil) {
+			reservation.LockedUntil = *attempt.LockedUntil
+		}
+	}
+
+	if !reservation.LockedUntil.IsZero() {
+		return reservation
+	}
+
+	for i, key := range keys {
+		reservation.Reserved = append(reservation.Reserved, p.Fail(key.Scope, attempts[i], now))
+	}
+
+	return reservation
+}
+
+// Release digunakan untuk menghitung keadaan penghitung ke-i setelah passcode ternyata benar.
+// Penghitung pesanan dihapus (remove true), penghitung lain dikembalikan ke keadaan sebelum percobaan dicatat
+// selama belum diubah percobaan lain (ok false berarti penghitung dibiarkan).
+func (r PasscodeReservation) Release(i int, current PasscodeAttempt) (attempt PasscodeAttempt, remove bool, ok bool) {
+	if r.Keys[i].Scope == AttemptScopeOrder {
+		return PasscodeAttempt{}, true, true
+	}
+
+	if current.Failures != r.Reserved[i].Failures {
+		return current, false, false
+	}
+
+	return r.Previous[i], false, true
+}
+
+// ReservePasscodeAttempt adalah fungsi untuk mencatat percobaan passcode sebagai gagal sebelum passcode dicocokkan.
+// Baris penghitung dikunci dengan FOR UPDATE sehingga percobaan bersamaan diproses satu per satu.
+func ReservePasscodeAttempt(db *sql.DB, keys []AttemptKey, policy PasscodePolicy, now time.Time) (PasscodeReservation, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return PasscodeReservation{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return PasscodeReservation{}, err
+	}
+
+	// pastikan baris penghitung ada lalu kunci sesuai urutan keys agar tidak terjadi deadlock
+	attempts := make([]PasscodeAttempt, len(keys))
+	for i, key := range keys {
+		if _, err := tx.Exec(`INSERT INTO passcode_attempts (scope, key) VALUES ($1, $2) ON CONFLICT DO NOTHING`, key.Scope, key.Key); err != nil {
+			tx.Rollback()
+			return PasscodeReservation{}, err
+		}
+
+		err := tx.QueryRow(`SELECT failures, last_failed_at, locked_until FROM passcode_attempts WHERE scope = $1 AND key = $2 FOR UPDATE`,
+			key.Scope, key.Key).Scan(&attempts[i].Failures, &attempts[i].LastFailedAt, &attempts[i].LockedUntil)
+		if err != nil {
+			tx.Rollback()
+			return PasscodeReservation{}, err
+		}
+	}
+
+	// tidak ada yang dicatat jika salah satu penghitung sedang dikunci
+	reservation := policy.Reserve(keys, attempts, now)
+	if !reservation.LockedUntil.IsZero() {
+		tx.Rollback()
+		return reservation, nil
+	}
+
+	// simpan keadaan penghitung yang baru
+	for i, key := range keys {
+		attempt := reservation.Reserved[i]
+		_, err := tx.Exec(`UPDATE passcode_attempts SET failures = $1, last_failed_at = $2, locked_until = $3 WHERE scope = $4 AND key = $5`,
+			attempt.Failures, attempt.LastFailedAt, attempt.LockedUntil, key.Scope, key.Key)
+		if err != nil {
+			tx.Rollback()
+			return PasscodeReservation{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return PasscodeReservation{}, err
+	}
+
+	return reservation, nil
+}
+
+// ReleasePasscodeAttempt adalah fungsi untuk membatalkan percobaan yang sudah dicatat karena passcode ternyata benar
+func ReleasePasscodeAttempt(db *sql.DB, reservation PasscodeReservation) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for i, key := range reservation.Keys {
+		var current PasscodeAttempt
+		err := tx.QueryRow(`SELECT failures, last_failed_at, locked_until FROM passcode_attempts WHERE scope = $1 AND key = $2 FOR UPDATE`,
+			key.Scope, key.Key).Scan(&current.Failures, &current.LastFailedAt, &current.LockedUntil)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		attempt, remove, ok := reservation.Release(i, current)
+		switch {
+		case remove:
+			_, err = tx.Exec(`DELETE FROM passcode_attempts WHERE scope = $1 AND key = $2`, key.Scope, key.Key)
+		case ok:
+			_, err = tx.Exec(`UPDATE passcode_attempts SET failures = $1, last_failed_at = $2, locked_until = $3 WHERE scope = $4 AND key = $5`,
+				attempt.Failures, attempt.LastFailedAt, attempt.LockedUntil, key.Scope, key.Key)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// ResetPasscodeFailures adalah fungsi untuk menghapus penghitung percobaan passcode setelah berhasil
+func ResetPasscodeFailures(db *sql.DB, key AttemptKey) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	_, err := db.Exec(`DELETE FROM passcode_attempts WHERE scope = $1 AND key = $2`, key.Scope, key.Key)
+	return err
+}
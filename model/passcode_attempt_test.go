@@ -0,0 +1,120 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+var testPolicy = PasscodePolicy{
+	MaxOrderAttempts: 3,
+	MaxIPAttempts:    5,
+	BaseBackoff:      time.Second,
+	Lockout:          time.Minute,
+}
+
+func TestPasscodePolicyFailBackoff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// jeda berlipat dua setiap kegagalan lalu menjadi penguncian penuh saat batas tercapai
+	attempt := PasscodeAttempt{}
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, time.Minute} {
+		attempt = testPolicy.Fail(AttemptScopeOrder, attempt, now)
+		if attempt.Failures != i+1 {
+			t.Fatalf("kegagalan ke-%d: failures = %d", i+1, attempt.Failures)
+		}
+		if got := attempt.LockedUntil.Sub(now); got != want {
+			t.Errorf("kegagalan ke-%d: dikunci %v, seharusnya %v", i+1, got, want)
+		}
+	}
+
+	// penghitung IP memakai batasnya sendiri
+	attempt = PasscodeAttempt{}
+	for i := 0; i < 3; i++ {
+		attempt = testPolicy.Fail(AttemptScopeIP, attempt, now)
+	}
+	if got := attempt.LockedUntil.Sub(now); got != 4*time.Second {
+		t.Errorf("IP gagal 3 kali: dikunci %v, seharusnya 4s", got)
+	}
+}
+
+func TestPasscodePolicyFailCapsBackoff(t *testing.T) {
+	policy := PasscodePolicy{MaxOrderAttempts: 100, BaseBackoff: 10 * time.Second, Lockout: 30 * time.Second}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	attempt := PasscodeAttempt{}
+	for i := 0; i < 5; i++ {
+		attempt = policy.Fail(AttemptScopeOrder, attempt, now)
+	}
+	if got := attempt.LockedUntil.Sub(now); got != 30*time.Second {
+		t.Errorf("jeda = %v, seharusnya dibatasi 30s", got)
+	}
+}
+
+func TestPasscodePolicyFailRestartsAfterLockout(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	attempt := PasscodeAttempt{}
+	for i := 0; i < 3; i++ {
+		attempt = testPolicy.Fail(AttemptScopeOrder, attempt, now)
+	}
+
+	// kegagalan baru setelah penguncian dan masa penguncian berakhir dihitung dari awal
+	later := now.Add(2 * time.Minute)
+	attempt = testPolicy.Fail(AttemptScopeOrder, attempt, later)
+	if attempt.Failures != 1 || attempt.LockedUntil.Sub(later) != time.Second {
+		t.Errorf("failures = %d, dikunci %v, seharusnya dimulai ulang", attempt.Failures, attempt.LockedUntil.Sub(later))
+	}
+}
+
+func TestPasscodePolicyReserve(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	keys := []AttemptKey{{Scope: AttemptScopeOrder, Key: "order-1"}, {Scope: AttemptScopeIP, Key: "192.0.2.1"}}
+
+	reservation := testPolicy.Reserve(keys, []PasscodeAttempt{{}, {}}, now)
+	if !reservation.LockedUntil.IsZero() {
+		t.Fatalf("penghitung kosong tidak boleh dikunci: %v", reservation.LockedUntil)
+	}
+	if len(reservation.Reserved) != 2 || reservation.Reserved[0].Failures != 1 || reservation.Reserved[1].Failures != 1 {
+		t.Fatalf("percobaan harus dicatat pada kedua penghitung: %+v", reservation.Reserved)
+	}
+
+	// percobaan berikutnya selama jeda ditolak tanpa dicatat
+	next := testPolicy.Reserve(keys, reservation.Reserved, now.Add(500*time.Millisecond))
+	if !next.LockedUntil.Equal(now.Add(time.Second)) {
+		t.Errorf("lockedUntil = %v, seharusnya %v", next.LockedUntil, now.Add(time.Second))
+	}
+	if next.Reserved != nil {
+		t.Errorf("percobaan yang ditolak tidak boleh dicatat: %+v", next.Reserved)
+	}
+
+	// setelah jeda berakhir percobaan dicatat lagi
+	after := testPolicy.Reserve(keys, reservation.Reserved, now.Add(time.Second))
+	if !after.LockedUntil.IsZero() || after.Reserved[0].Failures != 2 {
+		t.Errorf("percobaan setelah jeda: lockedUntil %v, reserved %+v", after.LockedUntil, after.Reserved)
+	}
+}
+
+func TestPasscodeReservationRelease(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	keys := []AttemptKey{{Scope: AttemptScopeOrder, Key: "order-1"}, {Scope: AttemptScopeIP, Key: "192.0.2.1"}}
+	previousIP := testPolicy.Fail(AttemptScopeIP, PasscodeAttempt{}, now.Add(-time.Hour))
+
+	reservation := testPolicy.Reserve(keys, []PasscodeAttempt{{}, previousIP}, now)
+
+	// penghitung pesanan dihapus
+	if _, remove, ok := reservation.Release(0, reservation.Reserved[0]); !remove || !ok {
+		t.Errorf("penghitung pesanan harus dihapus, remove %v ok %v", remove, ok)
+	}
+
+	// penghitung IP dikembalikan ke keadaan sebelum percobaan
+	attempt, remove, ok := reservation.Release(1, reservation.Reserved[1])
+	if remove || !ok || attempt.Failures != previousIP.Failures {
+		t.Errorf("penghitung IP = %+v (remove %v ok %v), seharusnya kembali %+v", attempt, remove, ok, previousIP)
+	}
+
+	// penghitung IP yang sudah diubah percobaan lain dibiarkan
+	changed := testPolicy.Fail(AttemptScopeIP, reservation.Reserved[1], now)
+	if attempt, _, ok := reservation.Release(1, changed); ok || attempt.Failures != changed.Failures {
+		t.Errorf("penghitung IP yang berubah tidak boleh dikembalikan: %+v ok %v", attempt, ok)
+	}
+}
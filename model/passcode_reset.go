@@ -0,0 +1,124 @@
+package model
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// RecoverPasscode adalah representasi dari permintaan pemulihan passcode oleh pelanggan di API
+type RecoverPasscode struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasscode adalah representasi dari penukaran token pemulihan menjadi passcode baru di API
+type ResetPasscode struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// PasscodeResetToken adalah token sekali pakai untuk mengganti passcode pesanan yang hilang
+type PasscodeResetToken struct {
+	ID        string
+	OrderID   string
+	TokenHash string // hanya hash yang disimpan, token asli dikirim ke email pelanggan
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// ErrResetTokenInvalid adalah error ketika token pemulihan tidak ditemukan, sudah dipakai, atau kedaluwarsa
+var ErrResetTokenInvalid = NewError(ErrorKindValidation, "invalid_reset_token", "Token pemulihan passcode tidak valid atau sudah kedaluwarsa")
+
+var (
+	// ErrInvalidPasscodeRecovery adalah error ketika data pemulihan passcode pada request tidak valid
+	ErrInvalidPasscodeRecovery = NewError(ErrorKindValidation, "invalid_passcode_recovery", "Data pemulihan passcode tidak valid")
+
+	// ErrInvalidPasscodeReset adalah error ketika data reset passcode pada request tidak valid
+	ErrInvalidPasscodeReset = NewError(ErrorKindValidation, "invalid_passcode_reset", "Data reset passcode tidak valid")
+)
+
+// HashToken digunakan untuk membuat hash token acak (pemulihan passcode, keranjang) yang disimpan di database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// InsertPasscodeResetToken adalah fungsi untuk menyimpan token pemulihan passcode
+func InsertPasscodeResetToken(db *sql.DB, token PasscodeResetToken) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := `INSERT INTO passcode_reset_tokens (id, order_id, token_hash, created_at, expires_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := db.Exec(query, token.ID, token.OrderID, token.TokenHash, token.CreatedAt, token.ExpiresAt)
+
+	return err
+}
+
+// CountPasscodeResetTokenSince adalah fungsi untuk menghitung token pemulihan yang dibuat untuk pesanan sejak waktu tertentu
+func CountPasscodeResetTokenSince(db *sql.DB, orderID string, since time.Time) (int, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return 0, errors.New("tidak ada koneksi ke database")
+	}
+
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM passcode_reset_tokens WHERE order_id = $1 AND created_at >= $2`, orderID, since).Scan(&count)
+
+	return count, err
+}
+
+// RedeemPasscodeResetToken adalah fungsi untuk menukarkan token pemulihan dengan hash passcode baru.
+// Token ditandai terpakai dan token lain milik pesanan yang sama ikut dinonaktifkan.
+func RedeemPasscodeResetToken(db *sql.DB, orderID string, tokenHash string, passcodeHash string, now time.Time) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// kunci token agar tidak dapat ditukarkan dua kali secara bersamaan
+	var token PasscodeResetToken
+	err = tx.QueryRow(`SELECT id, expires_at, used_at FROM passcode_reset_tokens WHERE order_id = $1 AND token_hash = $2 FOR UPDATE`,
+		orderID, tokenHash).Scan(&token.ID, &token.ExpiresAt, &token.UsedAt)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrResetTokenInvalid
+		}
+		return err
+	}
+
+	if token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		tx.Rollback()
+		return ErrResetTokenInvalid
+	}
+
+	// tandai seluruh token pesanan yang belum dipakai sebagai terpakai
+	if _, err := tx.Exec(`UPDATE passcode_reset_tokens SET used_at = $1 WHERE order_id = $2 AND used_at IS NULL`, now, orderID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// ganti passcode pesanan
+	if _, err := tx.Exec(`UPDATE orders SET passcode = $1 WHERE id = $2`, passcodeHash, orderID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -9,26 +9,138 @@ import (
 
 // Product adalah representasi dari data produk di database dan API
 type Product struct {
-	ID        string `json:"id" binding:"len=0"` // mencegah ID diisi oleh user
-	Name      string `json:"name"`
-	Price     int64  `json:"price"`
-	IsDeleted *bool  `json:"is_deleted,omitempty"`
+	ID          string           `json:"id" binding:"len=0"` // mencegah ID diisi oleh user
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Price       int64            `json:"price"`
+	Stock       *int32           `json:"stock,omitempty" binding:"omitempty,min=0"` // nil berarti stok tidak diubah
+	CategoryIDs []string         `json:"categoryIds,omitempty"`                     // nil berarti kategori tidak diubah
+	Variants    []ProductVariant `json:"variants,omitempty" binding:"len=0"`
+	IsDeleted   *bool            `json:"is_deleted,omitempty"`
+
+	// batas jumlah per pesanan, nil berarti mengikuti ORDER_MAX_QUANTITY.
+	// Pada request, nil berarti tidak diubah dan 0 menghapus batas.
+	MaxOrderQuantity *int32 `json:"maxOrderQuantity,omitempty" binding:"omitempty,min=0"`
+}
+
+// ProductFilter adalah representasi dari parameter query untuk daftar produk di API
+type ProductFilter struct {
+	Name     string `form:"name"`
+	Category string `form:"category"` // termasuk seluruh sub-kategori
+	MinPrice *int64 `form:"minPrice" binding:"omitempty,min=0"`
+	MaxPrice *int64 `form:"maxPrice" binding:"omitempty,min=0"`
+	Sort     string `form:"sort" binding:"omitempty,oneof=name price createdAt"`
+	Order    string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+var (
+	// ErrProductNotFound adalah error ketika produk tidak ditemukan
+	ErrProductNotFound = NewError(ErrorKindNotFound, "product_not_found", "Produk tidak ditemukan")
+
+	// ErrInvalidProduct adalah error ketika data produk pada request tidak valid
+	ErrInvalidProduct = NewError(ErrorKindValidation, "invalid_product", "Data produk tidak valid")
+
+	// ErrInvalidProductSearch adalah error ketika parameter daftar atau pencarian produk tidak valid
+	ErrInvalidProductSearch = NewError(ErrorKindValidation, "invalid_search", "Parameter pencarian tidak valid")
+
+	// ErrInvalidPriceRange adalah error ketika harga minimum melebihi harga maksimum
+	ErrInvalidPriceRange = NewError(ErrorKindValidation, "invalid_price_range", "Harga minimum tidak boleh melebihi harga maksimum")
+
+	// ErrSearchKeywordRequired adalah error ketika kata kunci pencarian kosong
+	ErrSearchKeywordRequired = NewError(ErrorKindValidation, "search_keyword_required", "Kata kunci pencarian wajib diisi")
+)
+
+// PageMeta adalah representasi dari metadata halaman di API
+type PageMeta struct {
+	Total    int  `json:"total"`
+	Page     int  `json:"page"`
+	Limit    int  `json:"limit"`
+	NextPage *int `json:"nextPage"`
 }
 
-// SelectProduct adalah fungsi untuk mengambil data produk dari database
-func SelectProduct(db *sql.DB) ([]Product, error) {
+// ProductList adalah representasi dari daftar produk beserta metadata halaman di API
+type ProductList struct {
+	Data []Product `json:"data"`
+	Meta PageMeta  `json:"meta"`
+}
+
+// productSortColumns berisi kolom yang diizinkan untuk pengurutan produk
+var productSortColumns = map[string]string{
+	"name":      "name",
+	"price":     "price",
+	"createdAt": "created_at",
+}
+
+// SelectProduct adalah fungsi untuk mengambil data produk dari database sesuai filter, urutan dan halaman
+func SelectProduct(db *sql.DB, filter ProductFilter) ([]Product, int, error) {
 	// pastikan koneksi ke database tidak nil
 	if db == nil {
-		return nil, errors.New("tidak ada koneksi ke database")
+		return nil, 0, errors.New("tidak ada koneksi ke database")
+	}
+
+	// susun kondisi filter
+	conditions := []string{"is_deleted = FALSE"}
+	args := []any{}
+
+	if filter.Name != "" {
+		args = append(args, "%"+escapeLike(filter.Name)+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		conditions = append(conditions, fmt.Sprintf("price >= $%d", len(args)))
+	}
+
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		conditions = append(conditions, fmt.Sprintf("price <= $%d", len(args)))
+	}
+
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf(`id IN (
+			SELECT pc.product_id FROM product_categories pc WHERE pc.category_id IN (
+				WITH RECURSIVE tree AS (
+					SELECT id FROM categories WHERE id = $%d
+					UNION
+					SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+				)
+				SELECT id FROM tree
+			)
+		)`, len(args)))
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	// hitung total produk yang sesuai filter
+	var total int
+	if err := db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM products WHERE %s`, where), args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// tentukan urutan, gunakan id sebagai pembanding agar urutan stabil
+	column, ok := productSortColumns[filter.Sort]
+	if !ok {
+		column = "created_at"
+	}
+
+	direction := "ASC"
+	if filter.Order == "desc" {
+		direction = "DESC"
 	}
 
 	// query untuk mengambil data produk
-	query := `SELECT id, name, price FROM products WHERE is_deleted = FALSE`
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf(`SELECT id, name, description, price, stock, max_order_quantity FROM products WHERE %s ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d`,
+		where, column, direction, direction, len(args)-1, len(args))
 
 	// eksekusi query
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -36,15 +148,69 @@ func SelectProduct(db *sql.DB) ([]Product, error) {
 	products := []Product{}
 	for rows.Next() {
 		product := Product{}
-		err = rows.Scan(&product.ID, &product.Name, &product.Price)
+		err = rows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.Stock, &product.MaxOrderQuantity)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		products = append(products, product)
 	}
 
-	return products, nil
+	return products, total, nil
+}
+
+// ProductSearchResult adalah representasi dari hasil pencarian produk beserta relevansinya di API
+type ProductSearchResult struct {
+	Product
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"` // potongan nama dan deskripsi dalam HTML yang sudah di-escape, kata yang cocok diapit <mark>
+}
+
+// SearchProducts adalah fungsi untuk mencari produk menggunakan full-text search PostgreSQL
+func SearchProducts(db *sql.DB, keyword string, limit int) ([]ProductSearchResult, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// query pencarian dengan peringkat relevansi dan potongan teks yang disorot.
+	// Teks di-escape sebagai HTML sebelum disorot agar <mark> menjadi satu-satunya tag di highlight.
+	query := `
+	SELECT id, name, description, price, stock, max_order_quantity,
+		ts_rank(search_vector, q) AS rank,
+		ts_headline('simple',
+			replace(replace(replace(replace(replace(name || ' ' || description, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;'),
+			q, 'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15') AS highlight
+	FROM products, websearch_to_tsquery('simple', $1) q
+	WHERE is_deleted = FALSE AND search_vector @@ q
+	ORDER BY rank DESC, id
+	LIMIT $2`
+
+	// eksekusi query
+	rows, err := db.Query(query, keyword, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ubah data hasil query ke bentuk slice
+	results := []ProductSearchResult{}
+	for rows.Next() {
+		result := ProductSearchResult{}
+		err = rows.Scan(&result.ID, &result.Name, &result.Description, &result.Price, &result.Stock, &result.MaxOrderQuantity, &result.Rank, &result.Highlight)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// escapeLike digunakan untuk meng-escape karakter khusus pada pola LIKE
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
 }
 
 // SelectProductByID adalah fungsi untuk mengambil data produk berdasarkan ID dari database
@@ -55,11 +221,11 @@ func SelectProductByID(db *sql.DB, id string) (Product, error) {
 	}
 
 	// query untuk mengambil data produk berdasarkan ID
-	query := `SELECT id, name, price FROM products WHERE is_deleted = FALSE AND id = $1`
+	query := `SELECT id, name, description, price, stock, max_order_quantity FROM products WHERE is_deleted = FALSE AND id = $1`
 
 	// eksekusi query
 	product := Product{}
-	err := db.QueryRow(query, id).Scan(&product.ID, &product.Name, &product.Price)
+	err := db.QueryRow(query, id).Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.Stock, &product.MaxOrderQuantity)
 	if err != nil {
 		return Product{}, err
 	}
@@ -83,7 +249,7 @@ func SelectProductIn(db *sql.DB, ids []string) ([]Product, error) {
 	}
 
 	// buat query dengan placeholder
-	query := fmt.Sprintf(`SELECT id, name, price FROM products WHERE is_deleted = FALSE AND id IN (%s)`, strings.Join(placeholders, ","))
+	query := fmt.Sprintf(`SELECT id, name, description, price, stock, max_order_quantity FROM products WHERE is_deleted = FALSE AND id IN (%s)`, strings.Join(placeholders, ","))
 
 	// eksekusi query dengan args berisi id-id produk
 	rows, err := db.Query(query, args...)
@@ -96,7 +262,7 @@ func SelectProductIn(db *sql.DB, ids []string) ([]Product, error) {
 	products := []Product{}
 	for rows.Next() {
 		product := Product{}
-		err = rows.Scan(&product.ID, &product.Name, &product.Price)
+		err = rows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.Stock, &product.MaxOrderQuantity)
 		if err != nil {
 			return nil, err
 		}
@@ -107,46 +273,95 @@ func SelectProductIn(db *sql.DB, ids []string) ([]Product, error) {
 	return products, nil
 }
 
-// InsertProduct adalah fungsi untuk menyimpan data produk ke database
-func InsertProduct(db *sql.DB, product Product) error {
+// InsertProduct adalah fungsi untuk menyimpan data produk beserta kategori dan audit log-nya ke database
+func InsertProduct(db *sql.DB, product Product, audit *AuditLog) error {
 	// pastikan koneksi ke database tidak nil
 	if db == nil {
 		return errors.New("gagal melakukan koneksi ke database")
 	}
 
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
 	// query untuk insert data produk
-	query := `INSERT INTO products (id, name, price) VALUES ($1, $2, $3)`
+	query := `INSERT INTO products (id, name, description, price, stock, max_order_quantity, created_at) VALUES ($1, $2, $3, $4, COALESCE($5, 0), $6, NOW())`
 
 	// eksekusi query
-	_, err := db.Exec(query, product.ID, product.Name, product.Price)
+	_, err = tx.Exec(query, product.ID, product.Name, product.Description, product.Price, product.Stock, product.MaxOrderQuantity)
 	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// simpan perubahan kategori dan audit log di transaction yang sama
+	if err := saveProductChange(tx, product, audit); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
 		return err
 	}
 
 	return nil
 }
 
-// UpdateProduct adalah fungsi untuk mengubah data produk di database
-func UpdateProduct(db *sql.DB, product Product) error {
+// UpdateProduct adalah fungsi untuk mengubah data produk beserta kategori dan audit log-nya di database
+func UpdateProduct(db *sql.DB, product Product, audit *AuditLog) error {
 	// pastikan koneksi ke database tidak nil
 	if db == nil {
 		return errors.New("gagal melakukan koneksi ke database")
 	}
 
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
 	// query untuk update data produk
-	query := `UPDATE products SET name = $1, price = $2 WHERE id = $3`
+	query := `UPDATE products SET name = $1, description = $2, price = $3, stock = COALESCE($4, stock), max_order_quantity = $5 WHERE id = $6`
 
 	// eksekusi query
-	_, err := db.Exec(query, product.Name, product.Price, product.ID)
+	_, err = tx.Exec(query, product.Name, product.Description, product.Price, product.Stock, product.MaxOrderQuantity, product.ID)
 	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// simpan perubahan kategori dan audit log di transaction yang sama
+	if err := saveProductChange(tx, product, audit); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
 		return err
 	}
 
 	return nil
 }
 
-// DeleteProduct adalah fungsi untuk menghapus data produk dari database
-func DeleteProduct(db *sql.DB, id string) error {
+// saveProductChange adalah fungsi untuk mengganti kategori produk (nil berarti tidak diubah) dan menyimpan audit log-nya
+func saveProductChange(tx *sql.Tx, product Product, audit *AuditLog) error {
+	if product.CategoryIDs != nil {
+		if err := replaceProductCategories(tx, product.ID, product.CategoryIDs); err != nil {
+			return err
+		}
+	}
+
+	return insertAuditLog(tx, audit)
+}
+
+// DeleteProduct adalah fungsi untuk menghapus data produk dari database dan menyimpan audit log-nya
+func DeleteProduct(db *sql.DB, id string, audit *AuditLog) error {
 	// pastikan koneksi ke database tidak nil
 	if db == nil {
 		return errors.New("gagal melakukan koneksi ke database")
@@ -156,10 +371,5 @@ func DeleteProduct(db *sql.DB, id string) error {
 	query := `UPDATE products SET is_deleted = TRUE WHERE id = $1`
 
 	// eksekusi query
-	_, err := db.Exec(query, id)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return execWithAudit(db, audit, query, id)
 }
@@ -0,0 +1,588 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PromoType adalah jenis potongan dari kode promo
+type PromoType string
+
+const (
+	PromoTypePercentage   PromoType = "percentage"
+	PromoTypeFixed        PromoType = "fixed"
+	PromoTypeFreeShipping PromoType = "free_shipping"
+)
+
+// PromoCode adalah representasi dari data kode promo di database dan API
+type PromoCode struct {
+	ID                 string     `json:"id" binding:"len=0"` // mencegah ID diisi oleh user
+	Code               string     `json:"code"`
+	Type               PromoType  `json:"type" binding:"omitempty,oneof=percentage fixed free_shipping"`
+	Value              int64      `json:"value" binding:"min=0"`                           // persen (1-100) atau nominal potongan
+	MaxDiscount        *int64     `json:"maxDiscount,omitempty" binding:"omitempty,min=1"` // batas potongan untuk jenis persentase
+	MinOrderAmount     int64      `json:"minOrderAmount" binding:"min=0"`
+	StartsAt           *time.Time `json:"startsAt,omitempty"`
+	EndsAt             *time.Time `json:"endsAt,omitempty"`
+	UsageLimit         *int32     `json:"usageLimit,omitempty" binding:"omitempty,min=1"`
+	UsageLimitPerEmail *int32     `json:"usageLimitPerEmail,omitempty" binding:"omitempty,min=1"`
+	IsActive           *bool      `json:"isActive,omitempty"`
+	ProductIDs         []string   `json:"productIds,omitempty"`  // kosong berarti berlaku untuk semua produk
+	CategoryIDs        []string   `json:"categoryIds,omitempty"` // termasuk seluruh sub-kategori
+	UsedCount          int32      `json:"usedCount" binding:"len=0"`
+}
+
+// OrderDiscount adalah representasi dari baris potongan pada pesanan di database dan API
+type OrderDiscount struct {
+	ID          string    `json:"id"`
+	OrderID     string    `json:"orderId"`
+	PromoCodeID string    `json:"promoCodeId"`
+	Code        string    `json:"code"`
+	Type        PromoType `json:"type"`
+	Amount      int64     `json:"amount"`
+}
+
+var (
+	// ErrPromoNotFound adalah error ketika kode promo tidak ditemukan
+	ErrPromoNotFound = NewError(ErrorKindNotFound, "promo_not_found", "Kode promo tidak ditemukan")
+
+	// ErrPromoInactive adalah error ketika kode promo tidak aktif atau di luar masa berlaku
+	ErrPromoInactive = NewError(ErrorKindValidation, "promo_inactive", "Kode promo tidak berlaku")
+
+	// ErrPromoMinOrder adalah error ketika total belanja belum mencapai minimum kode promo
+	ErrPromoMinOrder = NewError(ErrorKindValidation, "promo_min_order", "Total belanja belum mencapai minimum kode promo")
+
+	// ErrPromoNotApplicable adalah error ketika tidak ada produk yang memenuhi cakupan kode promo
+	ErrPromoNotApplicable = NewError(ErrorKindValidation, "promo_not_applicable", "Kode promo tidak berlaku untuk produk yang dipesan")
+
+	// ErrPromoUsageExceeded adalah error ketika batas pemakaian kode promo sudah tercapai
+	ErrPromoUsageExceeded = NewError(ErrorKindConflict, "promo_usage_exceeded", "Batas pemakaian kode promo sudah tercapai")
+
+	// ErrDuplicatePromoCode adalah error ketika kode promo sudah digunakan oleh promo lain
+	ErrDuplicatePromoCode = NewError(ErrorKindConflict, "promo_code_taken", "Kode promo sudah digunakan")
+
+	// ErrPromoScopeNotFound adalah error ketika produk atau kategori pada cakupan promo tidak ditemukan
+	ErrPromoScopeNotFound = NewError(ErrorKindValidation, "promo_scope_not_found", "Produk atau kategori pada cakupan promo tidak ditemukan")
+
+	// ErrInvalidPromo adalah error ketika data kode promo pada request tidak valid
+	ErrInvalidPromo = NewError(ErrorKindValidation, "invalid_promo", "Data kode promo tidak valid")
+)
+
+// promoColumns adalah daftar kolom kode promo beserta jumlah pemakaiannya (pesanan yang tidak batal/kedaluwarsa)
+const promoColumns = `p.id, p.code, p.type, p.value, p.max_discount, p.min_order_amount, p.starts_at, p.ends_at,
+	p.usage_limit, p.usage_limit_per_email, p.is_active,
+	(SELECT COUNT(*) FROM order_discounts d JOIN orders o ON o.id = d.order_id
+		WHERE d.promo_code_id = p.id AND o.status NOT IN ('cancelled', 'expired')) AS used_count`
+
+// NormalizePromoCode digunakan untuk menyeragamkan penulisan kode promo
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Calculate digunakan untuk menghitung besar potongan dari subtotal produk yang memenuhi cakupan promo
+func (p PromoCode) Calculate(eligibleSubtotal int64, shippingFee int64) int64 {
+	switch p.Type {
+	case PromoTypePercentage:
+		discount := eligibleSubtotal * p.Value / 100
+		if p.MaxDiscount != nil && discount > *p.MaxDiscount {
+			discount = *p.MaxDiscount
+		}
+		return discount
+	case PromoTypeFixed:
+		return min(p.Value, eligibleSubtotal)
+	case PromoTypeFreeShipping:
+		return shippingFee
+	default:
+		return 0
+	}
+}
+
+// IsValidAt digunakan untuk memeriksa apakah kode promo aktif dan berada dalam masa berlaku
+func (p PromoCode) IsValidAt(now time.Time) bool {
+	if p.IsActive != nil && !*p.IsActive {
+		return false
+	}
+
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+
+	if p.EndsAt != nil && now.After(*p.EndsAt) {
+		return false
+	}
+
+	return true
+}
+
+// Evaluate digunakan untuk memvalidasi kode promo terhadap detail pesanan lalu menghitung potongannya.
+// eligible berisi ID produk yang memenuhi cakupan promo. Batas pemakaian diperiksa terpisah dengan CheckUsage.
+func (p PromoCode) Evaluate(details []OrderDetail, eligible map[string]bool, shippingFee int64, now time.Time) (OrderDiscount, error) {
+	// pastikan kode promo masih berlaku
+	if !p.IsValidAt(now) {
+		return OrderDiscount{}, ErrPromoInactive
+	}
+
+	// pastikan total belanja memenuhi minimum
+	var subtotal, eligibleSubtotal int64
+	for _, detail := range details {
+		subtotal += detail.Total
+		if eligible[detail.ProductID] {
+			eligibleSubtotal += detail.Total
+		}
+	}
+
+	if subtotal < p.MinOrderAmount {
+		return OrderDiscount{}, ErrPromoMinOrder
+	}
+
+	// minimal satu produk harus memenuhi cakupan promo
+	if eligibleSubtotal == 0 {
+		return OrderDiscount{}, ErrPromoNotApplicable
+	}
+
+	return OrderDiscount{
+		PromoCodeID: p.ID,
+		Code:        p.Code,
+		Type:        p.Type,
+		Amount:      p.Calculate(eligibleSubtotal, shippingFee),
+	}, nil
+}
+
+// CheckUsage digunakan untuk memeriksa batas pemakaian total (dari UsedCount) dan per email (dari usedByEmail).
+// Pemakaian hanya dihitung dari pesanan yang tidak batal.
+func (p PromoCode) CheckUsage(usedByEmail int32) error {
+	if p.UsageLimit != nil && p.UsedCount >= *p.UsageLimit {
+		return ErrPromoUsageExceeded
+	}
+
+	if p.UsageLimitPerEmail != nil && usedByEmail >= *p.UsageLimitPerEmail {
+		return ErrPromoUsageExceeded
+	}
+
+	return nil
+}
+
+// ApplyPromoCode adalah fungsi untuk memvalidasi kode promo terhadap pesanan dan menghitung potongannya
+func ApplyPromoCode(db *sql.DB, code string, email string, details []OrderDetail, shippingFee int64, now time.Time) (OrderDiscount, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return OrderDiscount{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	// ambil data kode promo
+	promo, err := SelectPromoCodeByCode(db, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return OrderDiscount{}, ErrPromoNotFound
+		}
+		return OrderDiscount{}, err
+	}
+
+	// ambil produk yang memenuhi cakupan promo
+	productIDs := []string{}
+	for _, detail := range details {
+		productIDs = append(productIDs, detail.ProductID)
+	}
+
+	eligible, err := selectPromoEligibleProducts(db, promo.ID, productIDs)
+	if err != nil {
+		return OrderDiscount{}, err
+	}
+
+	// validasi kode promo dan hitung potongannya
+	discount, err := promo.Evaluate(details, eligible, shippingFee, now)
+	if err != nil {
+		return OrderDiscount{}, err
+	}
+
+	// periksa batas pemakaian (diperiksa ulang dengan penguncian saat pesanan disimpan)
+	if err := checkPromoUsage(db, promo, email); err != nil {
+		return OrderDiscount{}, err
+	}
+
+	return discount, nil
+}
+
+// queryer adalah abstraksi dari *sql.DB dan *sql.Tx untuk query yang bisa dijalankan di dalam maupun di luar transaction
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// checkPromoUsage adalah fungsi untuk memeriksa batas pemakaian kode promo secara total dan per email
+func checkPromoUsage(q queryer, promo PromoCode, email string) error {
+	// pemakaian per email hanya dihitung jika promo membatasinya
+	var usedByEmail int32
+	if promo.UsageLimitPerEmail != nil {
+		query := `SELECT COUNT(*) FROM order_discounts d JOIN orders o ON o.id = d.order_id
+			WHERE d.promo_code_id = $1 AND LOWER(o.email) = LOWER($2) AND o.status NOT IN ('cancelled', 'expired')`
+
+		if err := q.QueryRow(query, promo.ID, email).Scan(&usedByEmail); err != nil {
+			return err
+		}
+	}
+
+	return promo.CheckUsage(usedByEmail)
+}
+
+// lockPromoUsage adalah fungsi untuk mengunci kode promo dan memeriksa ulang batas pemakaiannya di dalam transaction
+func lockPromoUsage(tx *sql.Tx, promoID string, email string) error {
+	// kunci baris kode promo agar pemakaian bersamaan tidak melewati batas
+	if _, err := tx.Exec(`SELECT id FROM promo_codes WHERE id = $1 FOR UPDATE`, promoID); err != nil {
+		return err
+	}
+
+	// ambil data terbaru kode promo beserta jumlah pemakaiannya
+	promo, err := scanPromoCode(tx.QueryRow(fmt.Sprintf(`SELECT %s FROM promo_codes p WHERE p.id = $1`, promoColumns), promoID))
+	if err != nil {
+		return err
+	}
+
+	return checkPromoUsage(tx, promo, email)
+}
+
+// selectPromoEligibleProducts adalah fungsi untuk mengambil ID produk yang termasuk cakupan kode promo
+func selectPromoEligibleProducts(q queryer, promoID string, productIDs []string) (map[string]bool, error) {
+	eligible := make(map[string]bool)
+	if len(productIDs) == 0 {
+		return eligible, nil
+	}
+
+	// buat placeholder & args untuk query
+	placeholders := make([]string, len(productIDs))
+	args := []any{promoID}
+	for i, id := range productIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+2)
+		args = append(args, id)
+	}
+
+	// promo tanpa cakupan berlaku untuk semua produk, kategori mencakup seluruh sub-kategori
+	query := fmt.Sprintf(`
+	SELECT p.id FROM products p WHERE p.id IN (%s) AND (
+		(NOT EXISTS (SELECT 1 FROM promo_code_products WHERE promo_code_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM promo_code_categories WHERE promo_code_id = $1))
+		OR p.id IN (SELECT product_id FROM promo_code_products WHERE promo_code_id = $1)
+		OR p.id IN (
+			SELECT pc.product_id FROM product_categories pc WHERE pc.category_id IN (
+				WITH RECURSIVE tree AS (
+					SELECT category_id AS id FROM promo_code_categories WHERE promo_code_id = $1
+					UNION
+					SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+				)
+				SELECT id FROM tree
+			)
+		)
+	)`, strings.Join(placeholders, ","))
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		eligible[id] = true
+	}
+
+	return eligible, nil
+}
+
+// SelectPromoCode adalah fungsi untuk mengambil seluruh data kode promo dari database
+func SelectPromoCode(db *sql.DB) ([]PromoCode, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	rows, err := db.Query(fmt.Sprintf(`SELECT %s FROM promo_codes p ORDER BY p.code`, promoColumns))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ubah data hasil query ke bentuk slice
+	promos := []PromoCode{}
+	for rows.Next() {
+		promo, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		promos = append(promos, promo)
+	}
+
+	// lengkapi cakupan produk dan kategori
+	for i := range promos {
+		if err := selectPromoScope(db, &promos[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return promos, nil
+}
+
+// SelectPromoCodeByID adalah fungsi untuk mengambil data kode promo berdasarkan ID dari database
+func SelectPromoCodeByID(db *sql.DB, id string) (PromoCode, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return PromoCode{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	promo, err := scanPromoCode(db.QueryRow(fmt.Sprintf(`SELECT %s FROM promo_codes p WHERE p.id = $1`, promoColumns), id))
+	if err != nil {
+		return PromoCode{}, err
+	}
+
+	// lengkapi cakupan produk dan kategori
+	if err := selectPromoScope(db, &promo); err != nil {
+		return PromoCode{}, err
+	}
+
+	return promo, nil
+}
+
+// SelectPromoCodeByCode adalah fungsi untuk mengambil data kode promo berdasarkan kodenya dari database
+func SelectPromoCodeByCode(db *sql.DB, code string) (PromoCode, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return PromoCode{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	query := fmt.Sprintf(`SELECT %s FROM promo_codes p WHERE p.code = $1`, promoColumns)
+	return scanPromoCode(db.QueryRow(query, NormalizePromoCode(code)))
+}
+
+// InsertPromoCode adalah fungsi untuk menyimpan data kode promo beserta cakupan dan audit log-nya ke database
+func InsertPromoCode(db *sql.DB, promo PromoCode, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// query untuk insert data kode promo
+	query := `INSERT INTO promo_codes (id, code, type, value, max_discount, min_order_amount, starts_at, ends_at, usage_limit, usage_limit_per_email, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE($11, TRUE))`
+	_, err = tx.Exec(query, promo.ID, promo.Code, promo.Type, promo.Value, promo.MaxDiscount, promo.MinOrderAmount,
+		promo.StartsAt, promo.EndsAt, promo.UsageLimit, promo.UsageLimitPerEmail, promo.IsActive)
+	if err != nil {
+		tx.Rollback()
+		return mapPromoError(err)
+	}
+
+	// simpan cakupan produk dan kategori
+	if err := replacePromoScope(tx, promo); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// simpan audit log di transaction yang sama
+	if err := insertAuditLog(tx, audit); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePromoCode adalah fungsi untuk mengubah data kode promo beserta cakupan dan audit log-nya di database
+func UpdatePromoCode(db *sql.DB, promo PromoCode, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// buat transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// query untuk update data kode promo
+	query := `UPDATE promo_codes SET code = $1, type = $2, value = $3, max_discount = $4, min_order_amount = $5, starts_at = $6, ends_at = $7,
+		usage_limit = $8, usage_limit_per_email = $9, is_active = COALESCE($10, is_active) WHERE id = $11`
+	_, err = tx.Exec(query, promo.Code, promo.Type, promo.Value, promo.MaxDiscount, promo.MinOrderAmount, promo.StartsAt, promo.EndsAt,
+		promo.UsageLimit, promo.UsageLimitPerEmail, promo.IsActive, promo.ID)
+	if err != nil {
+		tx.Rollback()
+		return mapPromoError(err)
+	}
+
+	// simpan cakupan produk dan kategori
+	if err := replacePromoScope(tx, promo); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// simpan audit log di transaction yang sama
+	if err := insertAuditLog(tx, audit); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// DeactivatePromoCode adalah fungsi untuk menonaktifkan kode promo (riwayat pemakaian tetap disimpan) dan menyimpan audit log-nya
+func DeactivatePromoCode(db *sql.DB, id string, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// eksekusi query
+	return execWithAudit(db, audit, `UPDATE promo_codes SET is_active = FALSE WHERE id = $1`, id)
+}
+
+// SelectOrderDiscountByOrderID adalah fungsi untuk mengambil data potongan berdasarkan ID pesanan
+func SelectOrderDiscountByOrderID(db *sql.DB, orderID string) ([]OrderDiscount, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk mengambil data potongan pesanan
+	query := `SELECT id, order_id, promo_code_id, code, type, amount FROM order_discounts WHERE order_id = $1 ORDER BY id`
+	rows, err := db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ambil data dari rows
+	discounts := []OrderDiscount{}
+	for rows.Next() {
+		discount := OrderDiscount{}
+		err := rows.Scan(&discount.ID, &discount.OrderID, &discount.PromoCodeID, &discount.Code, &discount.Type, &discount.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		discounts = append(discounts, discount)
+	}
+
+	return discounts, nil
+}
+
+// rowScanner adalah abstraksi dari *sql.Row dan *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanPromoCode digunakan untuk membaca satu baris kode promo
+func scanPromoCode(row rowScanner) (PromoCode, error) {
+	promo := PromoCode{}
+	err := row.Scan(&promo.ID, &promo.Code, &promo.Type, &promo.Value, &promo.MaxDiscount, &promo.MinOrderAmount, &promo.StartsAt, &promo.EndsAt,
+		&promo.UsageLimit, &promo.UsageLimitPerEmail, &promo.IsActive, &promo.UsedCount)
+	if err != nil {
+		return PromoCode{}, err
+	}
+
+	return promo, nil
+}
+
+// selectPromoScope digunakan untuk mengambil cakupan produk dan kategori dari kode promo
+func selectPromoScope(db *sql.DB, promo *PromoCode) error {
+	promo.ProductIDs = []string{}
+	promo.CategoryIDs = []string{}
+
+	rows, err := db.Query(`SELECT product_id FROM promo_code_products WHERE promo_code_id = $1 ORDER BY product_id`, promo.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		promo.ProductIDs = append(promo.ProductIDs, id)
+	}
+
+	rows, err = db.Query(`SELECT category_id FROM promo_code_categories WHERE promo_code_id = $1 ORDER BY category_id`, promo.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		promo.CategoryIDs = append(promo.CategoryIDs, id)
+	}
+
+	return nil
+}
+
+// replacePromoScope digunakan untuk mengganti cakupan produk dan kategori kode promo di dalam transaction
+func replacePromoScope(tx *sql.Tx, promo PromoCode) error {
+	if _, err := tx.Exec(`DELETE FROM promo_code_products WHERE promo_code_id = $1`, promo.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM promo_code_categories WHERE promo_code_id = $1`, promo.ID); err != nil {
+		return err
+	}
+
+	for _, id := range promo.ProductIDs {
+		if _, err := tx.Exec(`INSERT INTO promo_code_products (promo_code_id, product_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, promo.ID, id); err != nil {
+			return mapPromoError(err)
+		}
+	}
+
+	for _, id := range promo.CategoryIDs {
+		if _, err := tx.Exec(`INSERT INTO promo_code_categories (promo_code_id, category_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, promo.ID, id); err != nil {
+			return mapPromoError(err)
+		}
+	}
+
+	return nil
+}
+
+// mapPromoError digunakan untuk mengubah error constraint database menjadi error kode promo
+func mapPromoError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return ErrDuplicatePromoCode
+		case "23503":
+			return ErrPromoScopeNotFound
+		}
+	}
+
+	return err
+}
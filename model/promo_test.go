@@ -0,0 +1,73 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPromoCodeEvaluate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	maxDiscount := int64(3000)
+	promo := PromoCode{ID: "promo-1", Code: "HEMAT10", Type: PromoTypePercentage, Value: 10, MaxDiscount: &maxDiscount, MinOrderAmount: 20000}
+	details := []OrderDetail{
+		{ProductID: "p1", Total: 20000},
+		{ProductID: "p2", Total: 50000},
+	}
+
+	// potongan dihitung dari produk dalam cakupan saja
+	discount, err := promo.Evaluate(details, map[string]bool{"p1": true}, 10000, now)
+	if err != nil || discount.Amount != 2000 || discount.PromoCodeID != "promo-1" || discount.Code != "HEMAT10" {
+		t.Errorf("potongan = %+v (err %v), seharusnya 2.000", discount, err)
+	}
+
+	// potongan persen dibatasi MaxDiscount
+	if discount, _ := promo.Evaluate(details, map[string]bool{"p1": true, "p2": true}, 10000, now); discount.Amount != 3000 {
+		t.Errorf("potongan = %d, seharusnya dibatasi 3.000", discount.Amount)
+	}
+
+	// total belanja tepat sama dengan minimum memenuhi syarat
+	if _, err := promo.Evaluate(details[:1], map[string]bool{"p1": true}, 10000, now); err != nil {
+		t.Errorf("tepat minimum: %v, seharusnya berlaku", err)
+	}
+
+	ended := now.Add(-time.Hour)
+	expired := promo
+	expired.EndsAt = &ended
+	for name, tc := range map[string]struct {
+		promo    PromoCode
+		details  []OrderDetail
+		eligible map[string]bool
+		want     error
+	}{
+		"kedaluwarsa":      {expired, details, map[string]bool{"p1": true}, ErrPromoInactive},
+		"di bawah minimum": {promo, []OrderDetail{{ProductID: "p1", Total: 19999}}, map[string]bool{"p1": true}, ErrPromoMinOrder},
+		"di luar cakupan":  {promo, details, map[string]bool{}, ErrPromoNotApplicable},
+	} {
+		if _, err := tc.promo.Evaluate(tc.details, tc.eligible, 10000, now); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, seharusnya %v", name, err, tc.want)
+		}
+	}
+}
+
+func TestPromoCodeCheckUsage(t *testing.T) {
+	limit, perEmail := int32(10), int32(1)
+	promo := PromoCode{UsageLimit: &limit, UsageLimitPerEmail: &perEmail, UsedCount: 9}
+
+	if err := promo.CheckUsage(0); err != nil {
+		t.Errorf("pemakaian di bawah batas: %v", err)
+	}
+	if err := promo.CheckUsage(1); !errors.Is(err, ErrPromoUsageExceeded) {
+		t.Errorf("batas per email: %v, seharusnya ErrPromoUsageExceeded", err)
+	}
+
+	promo.UsedCount = 10
+	if err := promo.CheckUsage(0); !errors.Is(err, ErrPromoUsageExceeded) {
+		t.Errorf("batas total: %v, seharusnya ErrPromoUsageExceeded", err)
+	}
+
+	// promo tanpa batas dapat dipakai berapa kali pun
+	if err := (PromoCode{UsedCount: 1000}).CheckUsage(1000); err != nil {
+		t.Errorf("promo tanpa batas: %v", err)
+	}
+}
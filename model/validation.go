@@ -0,0 +1,15 @@
+package model
+
+// FieldError adalah representasi dari kesalahan validasi pada satu field request di API.
+// Code dipakai untuk mencari terjemahan pesan, Params mengisi nilai di dalam pesan (misalnya batas jumlah).
+type FieldError struct {
+	Field   string         `json:"field"` // path field sesuai JSON, misalnya products[1].quantity
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+// NewFieldError digunakan untuk membuat kesalahan validasi field dengan kode dan pesan bawaan
+func NewFieldError(field string, code string, message string) FieldError {
+	return FieldError{Field: field, Code: code, Message: message}
+}
@@ -0,0 +1,263 @@
+package model
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// VariantOptions adalah atribut pilihan dari varian produk, contoh: {"size": "XL", "colour": "red"}
+type VariantOptions map[string]string
+
+// Value digunakan untuk menyimpan VariantOptions sebagai JSONB di database
+func (o VariantOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return "{}", nil
+	}
+
+	value, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(value), nil
+}
+
+// Scan digunakan untuk membaca kolom JSONB dari database menjadi VariantOptions
+func (o *VariantOptions) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(value, o)
+	case string:
+		return json.Unmarshal([]byte(value), o)
+	default:
+		return fmt.Errorf("tipe data opsi varian tidak didukung: %T", src)
+	}
+}
+
+// ProductVariant adalah representasi dari data varian produk (SKU) di database dan API
+type ProductVariant struct {
+	ID        string         `json:"id" binding:"len=0"`        // mencegah ID diisi oleh user
+	ProductID string         `json:"productId" binding:"len=0"` // diambil dari URL
+	SKU       string         `json:"sku"`
+	Options   VariantOptions `json:"options"`
+	Price     *int64         `json:"price,omitempty" binding:"omitempty,min=0"` // nil berarti mengikuti harga produk
+	Stock     *int32         `json:"stock,omitempty" binding:"omitempty,min=0"` // nil berarti stok tidak diubah
+}
+
+// ErrDuplicateSKU adalah error ketika kode SKU sudah digunakan oleh varian lain
+var ErrDuplicateSKU = NewError(ErrorKindConflict, "sku_taken", "Kode SKU sudah digunakan")
+
+var (
+	// ErrInvalidVariant adalah error ketika data varian pada request tidak valid
+	ErrInvalidVariant = NewError(ErrorKindValidation, "invalid_variant", "Data varian tidak valid")
+
+	// ErrSKURequired adalah error ketika kode SKU varian kosong
+	ErrSKURequired = NewError(ErrorKindValidation, "sku_required", "Kode SKU wajib diisi")
+
+	// ErrVariantNotFound adalah error ketika varian produk tidak ditemukan
+	ErrVariantNotFound = NewError(ErrorKindNotFound, "variant_not_found", "Varian tidak ditemukan")
+)
+
+// EffectivePrice digunakan untuk mendapatkan harga varian, atau harga produk jika varian tidak menimpa harga
+func (v ProductVariant) EffectivePrice(product Product) int64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+
+	return product.Price
+}
+
+// SelectVariantByProductID adalah fungsi untuk mengambil data varian dari sebuah produk
+func SelectVariantByProductID(db *sql.DB, productID string) ([]ProductVariant, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk mengambil data varian
+	query := `SELECT id, product_id, sku, options, price, stock FROM product_variants WHERE is_deleted = FALSE AND product_id = $1 ORDER BY sku`
+
+	// eksekusi query
+	rows, err := db.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanVariants(rows)
+}
+
+// SelectVariantIn adalah fungsi untuk mengambil data varian berdasarkan ID-ID dari database
+func SelectVariantIn(db *sql.DB, ids []string) ([]ProductVariant, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// tidak perlu query jika tidak ada ID
+	if len(ids) == 0 {
+		return []ProductVariant{}, nil
+	}
+
+	// buat placeholder & args untuk query
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	// buat query dengan placeholder
+	query := fmt.Sprintf(`SELECT id, product_id, sku, options, price, stock FROM product_variants WHERE is_deleted = FALSE AND id IN (%s)`, strings.Join(placeholders, ","))
+
+	// eksekusi query dengan args berisi id-id varian
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanVariants(rows)
+}
+
+// SelectProductIDsWithVariants adalah fungsi untuk mengambil ID produk yang memiliki varian aktif
+func SelectProductIDsWithVariants(db *sql.DB, productIDs []string) (map[string]bool, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return nil, errors.New("tidak ada koneksi ke database")
+	}
+
+	// tidak perlu query jika tidak ada ID
+	result := make(map[string]bool)
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	// buat placeholder & args untuk query
+	placeholders := make([]string, len(productIDs))
+	args := make([]any, len(productIDs))
+	for i, id := range productIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	// buat query dengan placeholder
+	query := fmt.Sprintf(`SELECT DISTINCT product_id FROM product_variants WHERE is_deleted = FALSE AND product_id IN (%s)`, strings.Join(placeholders, ","))
+
+	// eksekusi query
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		result[id] = true
+	}
+
+	return result, nil
+}
+
+// SelectVariantByID adalah fungsi untuk mengambil data varian berdasarkan ID produk dan ID varian
+func SelectVariantByID(db *sql.DB, productID string, id string) (ProductVariant, error) {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return ProductVariant{}, errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk mengambil data varian
+	query := `SELECT id, product_id, sku, options, price, stock FROM product_variants WHERE is_deleted = FALSE AND product_id = $1 AND id = $2`
+
+	// eksekusi query
+	variant := ProductVariant{}
+	err := db.QueryRow(query, productID, id).Scan(&variant.ID, &variant.ProductID, &variant.SKU, &variant.Options, &variant.Price, &variant.Stock)
+	if err != nil {
+		return ProductVariant{}, err
+	}
+
+	return variant, nil
+}
+
+// InsertVariant adalah fungsi untuk menyimpan data varian produk beserta audit log-nya ke database
+func InsertVariant(db *sql.DB, variant ProductVariant, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk insert data varian
+	query := `INSERT INTO product_variants (id, product_id, sku, options, price, stock) VALUES ($1, $2, $3, $4, $5, COALESCE($6, 0))`
+
+	// eksekusi query
+	err := execWithAudit(db, audit, query, variant.ID, variant.ProductID, variant.SKU, variant.Options, variant.Price, variant.Stock)
+	return mapVariantError(err)
+}
+
+// UpdateVariant adalah fungsi untuk mengubah data varian produk beserta audit log-nya di database
+func UpdateVariant(db *sql.DB, variant ProductVariant, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk update data varian
+	query := `UPDATE product_variants SET sku = $1, options = $2, price = $3, stock = COALESCE($4, stock) WHERE product_id = $5 AND id = $6`
+
+	// eksekusi query
+	err := execWithAudit(db, audit, query, variant.SKU, variant.Options, variant.Price, variant.Stock, variant.ProductID, variant.ID)
+	return mapVariantError(err)
+}
+
+// DeleteVariant adalah fungsi untuk menghapus data varian produk dari database dan menyimpan audit log-nya
+func DeleteVariant(db *sql.DB, productID string, id string, audit *AuditLog) error {
+	// pastikan koneksi ke database tidak nil
+	if db == nil {
+		return errors.New("tidak ada koneksi ke database")
+	}
+
+	// query untuk menghapus data varian
+	query := `UPDATE product_variants SET is_deleted = TRUE WHERE product_id = $1 AND id = $2`
+
+	// eksekusi query
+	return execWithAudit(db, audit, query, productID, id)
+}
+
+// scanVariants digunakan untuk mengubah hasil query varian ke bentuk slice
+func scanVariants(rows *sql.Rows) ([]ProductVariant, error) {
+	variants := []ProductVariant{}
+	for rows.Next() {
+		variant := ProductVariant{}
+		err := rows.Scan(&variant.ID, &variant.ProductID, &variant.SKU, &variant.Options, &variant.Price, &variant.Stock)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+// mapVariantError digunakan untuk mengubah error unique constraint SKU menjadi ErrDuplicateSKU
+func mapVariantError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicateSKU
+	}
+
+	return err
+}
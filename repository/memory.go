@@ -0,0 +1,1858 @@
+package repository
+
+import (
+	"database/sql"
+	"html"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+)
+
+// pastikan implementasi in-memory memenuhi kontrak repository
+var (
+	_ ProductRepository         = (*MemoryProductRepository)(nil)
+	_ CategoryRepository        = (*MemoryCategoryRepository)(nil)
+	_ PromoRepository           = (*MemoryPromoRepository)(nil)
+	_ OrderRepository           = (*MemoryOrderRepository)(nil)
+	_ AdminRepository           = (*MemoryAdminRepository)(nil)
+	_ CustomerRepository        = (*MemoryCustomerRepository)(nil)
+	_ AuditRepository           = (*MemoryAuditRepository)(nil)
+	_ PasscodeAttemptRepository = (*MemoryPasscodeAttemptRepository)(nil)
+	_ EmailRepository           = (*MemoryEmailRepository)(nil)
+	_ CartRepository            = (*MemoryCartRepository)(nil)
+	_ IdempotencyRepository     = (*MemoryIdempotencyRepository)(nil)
+)
+
+// MemoryStore adalah penyimpanan data di memori yang dipakai bersama oleh repository in-memory,
+// digunakan untuk pengujian handler tanpa database
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	products          map[string]model.Product
+	productCreatedAt  map[string]time.Time
+	categories        map[string]model.Category
+	productCategories map[string][]string
+	variants          map[string]model.ProductVariant
+	promos            map[string]model.PromoCode
+
+	orders    map[string]model.Order
+	details   map[string][]model.OrderDetail
+	discounts map[string][]model.OrderDiscount
+	histories map[string][]model.OrderStatusHistory
+
+	admins    map[string]model.AdminUser
+	customers map[string]model.Customer
+
+	auditLogs        []model.AuditLog
+	passcodeAttempts map[model.AttemptKey]model.PasscodeAttempt
+	resetTokens      map[string]model.PasscodeResetToken
+	emails           []model.Email
+	carts            map[string]model.Cart
+	cartItems        map[string][]model.CartItem
+	idempotencyKeys  map[string]model.IdempotencyKey
+}
+
+// NewMemoryStore digunakan untuk membuat penyimpanan data di memori yang masih kosong
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		products:          make(map[string]model.Product),
+		productCreatedAt:  make(map[string]time.Time),
+		categories:        make(map[string]model.Category),
+		productCategories: make(map[string][]string),
+		variants:          make(map[string]model.ProductVariant),
+		promos:            make(map[string]model.PromoCode),
+		orders:            make(map[string]model.Order),
+		details:           make(map[string][]model.OrderDetail),
+		discounts:         make(map[string][]model.OrderDiscount),
+		histories:         make(map[string][]model.OrderStatusHistory),
+		admins:            make(map[string]model.AdminUser),
+		customers:         make(map[string]model.Customer),
+		passcodeAttempts:  make(map[model.AttemptKey]model.PasscodeAttempt),
+		resetTokens:       make(map[string]model.PasscodeResetToken),
+		carts:             make(map[string]model.Cart),
+		cartItems:         make(map[string][]model.CartItem),
+		idempotencyKeys:   make(map[string]model.IdempotencyKey),
+	}
+}
+
+// AddCategory digunakan untuk menambahkan data kategori ke penyimpanan
+func (s *MemoryStore) AddCategory(category model.Category) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	category.Children = nil
+	s.categories[category.ID] = category
+}
+
+// AddVariant digunakan untuk menambahkan data varian produk ke penyimpanan
+func (s *MemoryStore) AddVariant(variant model.ProductVariant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	variant.Stock = copyInt32(variant.Stock)
+	s.variants[variant.ID] = variant
+}
+
+// AddPromoCode digunakan untuk menambahkan data kode promo ke penyimpanan
+func (s *MemoryStore) AddPromoCode(promo model.PromoCode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	promo.Code = model.NormalizePromoCode(promo.Code)
+	s.promos[promo.Code] = promo
+}
+
+// MemoryProductRepository adalah implementasi ProductRepository di memori
+type MemoryProductRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryProductRepository digunakan untuk membuat ProductRepository berbasis memori
+func NewMemoryProductRepository(store *MemoryStore) *MemoryProductRepository {
+	return &MemoryProductRepository{store: store}
+}
+
+func (r *MemoryProductRepository) SelectProduct(filter model.ProductFilter) ([]model.Product, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// kumpulkan ID kategori beserta seluruh turunannya
+	var categoryIDs map[string]bool
+	if filter.Category != "" {
+		categoryIDs = r.store.categoryTree([]string{filter.Category})
+	}
+
+	// saring produk sesuai filter
+	products := []model.Product{}
+	for _, product := range r.store.products {
+		if product.IsDeleted != nil && *product.IsDeleted {
+			continue
+		}
+		if filter.Name != "" && !strings.Contains(strings.ToLower(product.Name), strings.ToLower(filter.Name)) {
+			continue
+		}
+		if filter.MinPrice != nil && product.Price < *filter.MinPrice {
+			continue
+		}
+		if filter.MaxPrice != nil && product.Price > *filter.MaxPrice {
+			continue
+		}
+		if categoryIDs != nil && !r.store.inCategories(product.ID, categoryIDs) {
+			continue
+		}
+
+		products = append(products, r.store.copyProduct(product))
+	}
+
+	// urutkan produk, gunakan id sebagai pembanding agar urutan stabil
+	sort.Slice(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		var less, equal bool
+		switch filter.Sort {
+		case "name":
+			less, equal = a.Name < b.Name, a.Name == b.Name
+		case "price":
+			less, equal = a.Price < b.Price, a.Price == b.Price
+		default:
+			ta, tb := r.store.productCreatedAt[a.ID], r.store.productCreatedAt[b.ID]
+			less, equal = ta.Before(tb), ta.Equal(tb)
+		}
+		if equal {
+			less = a.ID < b.ID
+		}
+		if filter.Order == "desc" {
+			return !less
+		}
+		return less
+	})
+
+	// ambil halaman yang diminta
+	total := len(products)
+	start := min((filter.Page-1)*filter.Limit, total)
+	end := min(start+filter.Limit, total)
+
+	return products[start:end], total, nil
+}
+
+func (r *MemoryProductRepository) SelectProductByID(id string) (model.Product, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	product, ok := r.store.products[id]
+	if !ok || (product.IsDeleted != nil && *product.IsDeleted) {
+		return model.Product{}, sql.ErrNoRows
+	}
+
+	return r.store.copyProduct(product), nil
+}
+
+func (r *MemoryProductRepository) SelectProductIn(ids []string) ([]model.Product, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	products := []model.Product{}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		product, ok := r.store.products[id]
+		if !ok || seen[id] || (product.IsDeleted != nil && *product.IsDeleted) {
+			continue
+		}
+
+		seen[id] = true
+		products = append(products, r.store.copyProduct(product))
+	}
+
+	return products, nil
+}
+
+func (r *MemoryProductRepository) SearchProducts(keyword string, limit int) ([]model.ProductSearchResult, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// produk cocok jika seluruh kata kunci muncul di nama atau deskripsi
+	words := strings.Fields(strings.ToLower(keyword))
+	results := []model.ProductSearchResult{}
+	for _, product := range r.store.products {
+		if product.IsDeleted != nil && *product.IsDeleted {
+			continue
+		}
+
+		text := product.Name + " " + product.Description
+		lower := strings.ToLower(text)
+		rank := 0.0
+		for _, word := range words {
+			count := strings.Count(lower, word)
+			if count == 0 {
+				rank = 0
+				break
+			}
+			rank += float64(count)
+		}
+
+		if rank == 0 {
+			continue
+		}
+
+		results = append(results, model.ProductSearchResult{
+			Product:   r.store.copyProduct(product),
+			Rank:      rank,
+			Highlight: highlight(text, words),
+		})
+	}
+
+	// urutkan berdasarkan relevansi
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank == results[j].Rank {
+			return results[i].ID < results[j].ID
+		}
+		return results[i].Rank > results[j].Rank
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// highlight digunakan untuk meng-escape teks sebagai HTML dan mengapit kata kunci dengan <mark>, seperti ts_headline
+func highlight(text string, words []string) string {
+	// tandai posisi byte yang cocok dengan salah satu kata kunci (tanpa membedakan huruf besar/kecil)
+	marked := make([]bool, len(text))
+	for _, word := range words {
+		for _, match := range regexp.MustCompile("(?i)"+regexp.QuoteMeta(word)).FindAllStringIndex(text, -1) {
+			for i := match[0]; i < match[1]; i++ {
+				marked[i] = true
+			}
+		}
+	}
+
+	// susun ulang teks yang sudah di-escape, bagian yang ditandai diapit <mark>
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		j := i
+		for j < len(text) && marked[j] == marked[i] {
+			j++
+		}
+
+		if marked[i] {
+			b.WriteString("<mark>" + html.EscapeString(text[i:j]) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(text[i:j]))
+		}
+		i = j
+	}
+
+	return b.String()
+}
+
+func (r *MemoryProductRepository) InsertProduct(product model.Product, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	categoryIDs, err := r.store.validCategoryIDs(product.CategoryIDs)
+	if err != nil {
+		return err
+	}
+
+	if product.Stock == nil {
+		stock := int32(0)
+		product.Stock = &stock
+	}
+
+	product.CategoryIDs = nil
+	product.Variants = nil
+	r.store.products[product.ID] = r.store.copyProduct(product)
+	r.store.productCreatedAt[product.ID] = time.Now()
+	if categoryIDs != nil {
+		r.store.productCategories[product.ID] = categoryIDs
+	}
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryProductRepository) UpdateProduct(product model.Product, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.products[product.ID]
+	if !ok {
+		return nil
+	}
+
+	categoryIDs, err := r.store.validCategoryIDs(product.CategoryIDs)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = product.Name
+	existing.Description = product.Description
+	existing.Price = product.Price
+	if product.Stock != nil {
+		existing.Stock = copyInt32(product.Stock)
+	}
+	existing.MaxOrderQuantity = copyInt32(product.MaxOrderQuantity)
+
+	r.store.products[product.ID] = existing
+	if categoryIDs != nil {
+		r.store.productCategories[product.ID] = categoryIDs
+	}
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryProductRepository) DeleteProduct(id string, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	product, ok := r.store.products[id]
+	if !ok {
+		return nil
+	}
+
+	deleted := true
+	product.IsDeleted = &deleted
+	r.store.products[id] = product
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryProductRepository) SelectProductCategoryIDs(productID string) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ids := append([]string{}, r.store.productCategories[productID]...)
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (r *MemoryProductRepository) SelectVariantByProductID(productID string) ([]model.ProductVariant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	variants := []model.ProductVariant{}
+	for _, variant := range r.store.variants {
+		if variant.ProductID == productID {
+			variants = append(variants, copyVariant(variant))
+		}
+	}
+
+	sort.Slice(variants, func(i, j int) bool { return variants[i].SKU < variants[j].SKU })
+	return variants, nil
+}
+
+func (r *MemoryProductRepository) SelectVariantIn(ids []string) ([]model.ProductVariant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	variants := []model.ProductVariant{}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		variant, ok := r.store.variants[id]
+		if !ok || seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		variants = append(variants, copyVariant(variant))
+	}
+
+	return variants, nil
+}
+
+func (r *MemoryProductRepository) SelectProductIDsWithVariants(productIDs []string) (map[string]bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wanted := make(map[string]bool)
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+
+	result := make(map[string]bool)
+	for _, variant := range r.store.variants {
+		if wanted[variant.ProductID] {
+			result[variant.ProductID] = true
+		}
+	}
+
+	return result, nil
+}
+
+func (r *MemoryProductRepository) SelectVariantByID(productID string, id string) (model.ProductVariant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	variant, ok := r.store.variants[id]
+	if !ok || variant.ProductID != productID {
+		return model.ProductVariant{}, sql.ErrNoRows
+	}
+
+	return copyVariant(variant), nil
+}
+
+func (r *MemoryProductRepository) InsertVariant(variant model.ProductVariant, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.skuTaken(variant.SKU, variant.ID) {
+		return model.ErrDuplicateSKU
+	}
+
+	if variant.Stock == nil {
+		stock := int32(0)
+		variant.Stock = &stock
+	}
+
+	r.store.variants[variant.ID] = copyVariant(variant)
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryProductRepository) UpdateVariant(variant model.ProductVariant, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.variants[variant.ID]
+	if !ok || existing.ProductID != variant.ProductID {
+		return missingRow(audit)
+	}
+
+	if r.store.skuTaken(variant.SKU, variant.ID) {
+		return model.ErrDuplicateSKU
+	}
+
+	existing.SKU = variant.SKU
+	existing.Options = variant.Options
+	existing.Price = variant.Price
+	if variant.Stock != nil {
+		existing.Stock = copyInt32(variant.Stock)
+	}
+
+	r.store.variants[variant.ID] = existing
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryProductRepository) DeleteVariant(productID string, id string, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	variant, ok := r.store.variants[id]
+	if !ok || variant.ProductID != productID {
+		return missingRow(audit)
+	}
+
+	delete(r.store.variants, id)
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+// MemoryCategoryRepository adalah implementasi CategoryRepository di memori
+type MemoryCategoryRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryCategoryRepository digunakan untuk membuat CategoryRepository berbasis memori
+func NewMemoryCategoryRepository(store *MemoryStore) *MemoryCategoryRepository {
+	return &MemoryCategoryRepository{store: store}
+}
+
+func (r *MemoryCategoryRepository) SelectCategory() ([]model.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	categories := []model.Category{}
+	for _, category := range r.store.categories {
+		categories = append(categories, category)
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name == categories[j].Name {
+			return categories[i].ID < categories[j].ID
+		}
+		return categories[i].Name < categories[j].Name
+	})
+
+	return categories, nil
+}
+
+func (r *MemoryCategoryRepository) SelectCategoryByID(id string) (model.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	category, ok := r.store.categories[id]
+	if !ok {
+		return model.Category{}, sql.ErrNoRows
+	}
+
+	return category, nil
+}
+
+func (r *MemoryCategoryRepository) InsertCategory(category model.Category, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// pastikan induk kategori ada
+	if category.ParentID != nil {
+		if _, ok := r.store.categories[*category.ParentID]; !ok {
+			return model.ErrCategoryNotFound
+		}
+	}
+
+	category.Children = nil
+	r.store.categories[category.ID] = category
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryCategoryRepository) UpdateCategory(category model.Category, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// pastikan induk kategori ada dan bukan turunan dari kategori ini
+	if category.ParentID != nil {
+		if _, ok := r.store.categories[*category.ParentID]; !ok {
+			return model.ErrCategoryNotFound
+		}
+
+		if r.store.categoryTree([]string{category.ID})[*category.ParentID] {
+			return model.ErrCategoryCycle
+		}
+	}
+
+	if _, ok := r.store.categories[category.ID]; !ok {
+		return missingRow(audit)
+	}
+
+	category.Children = nil
+	r.store.categories[category.ID] = category
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryCategoryRepository) DeleteCategory(id string, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// pastikan kategori tidak memiliki sub-kategori
+	for _, category := range r.store.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			return model.ErrCategoryHasChildren
+		}
+	}
+
+	if _, ok := r.store.categories[id]; !ok {
+		return missingRow(audit)
+	}
+
+	// relasi produk dan cakupan promo ikut terhapus seperti ON DELETE CASCADE
+	delete(r.store.categories, id)
+	for productID, ids := range r.store.productCategories {
+		r.store.productCategories[productID] = slices.DeleteFunc(ids, func(categoryID string) bool { return categoryID == id })
+	}
+	for code, promo := range r.store.promos {
+		promo.CategoryIDs = slices.DeleteFunc(promo.CategoryIDs, func(categoryID string) bool { return categoryID == id })
+		r.store.promos[code] = promo
+	}
+
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+// MemoryPromoRepository adalah implementasi PromoRepository di memori
+type MemoryPromoRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryPromoRepository digunakan untuk membuat PromoRepository berbasis memori
+func NewMemoryPromoRepository(store *MemoryStore) *MemoryPromoRepository {
+	return &MemoryPromoRepository{store: store}
+}
+
+func (r *MemoryPromoRepository) SelectPromoCode() ([]model.PromoCode, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	promos := []model.PromoCode{}
+	for _, code := range sortedPromoCodes(r.store.promos) {
+		promos = append(promos, r.store.promoWithUsage(r.store.promos[code]))
+	}
+
+	return promos, nil
+}
+
+func (r *MemoryPromoRepository) SelectPromoCodeByID(id string) (model.PromoCode, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	promo, ok := r.store.promoByID(id)
+	if !ok {
+		return model.PromoCode{}, sql.ErrNoRows
+	}
+
+	return r.store.promoWithUsage(promo), nil
+}
+
+func (r *MemoryPromoRepository) InsertPromoCode(promo model.PromoCode, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.promos[promo.Code]; ok {
+		return model.ErrDuplicatePromoCode
+	}
+
+	if err := r.store.checkPromoScope(promo); err != nil {
+		return err
+	}
+
+	if promo.IsActive == nil {
+		isActive := true
+		promo.IsActive = &isActive
+	}
+
+	r.store.promos[promo.Code] = copyPromo(promo)
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryPromoRepository) UpdatePromoCode(promo model.PromoCode, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.promoByID(promo.ID)
+	if !ok {
+		return missingRow(audit)
+	}
+
+	if other, ok := r.store.promos[promo.Code]; ok && other.ID != promo.ID {
+		return model.ErrDuplicatePromoCode
+	}
+
+	if err := r.store.checkPromoScope(promo); err != nil {
+		return err
+	}
+
+	if promo.IsActive == nil {
+		promo.IsActive = existing.IsActive
+	}
+
+	// kode promo dapat berubah sehingga data disimpan ulang dengan kode yang baru
+	delete(r.store.promos, existing.Code)
+	r.store.promos[promo.Code] = copyPromo(promo)
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryPromoRepository) DeactivatePromoCode(id string, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	promo, ok := r.store.promoByID(id)
+	if !ok {
+		return missingRow(audit)
+	}
+
+	isActive := false
+	promo.IsActive = &isActive
+	r.store.promos[promo.Code] = promo
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+// MemoryOrderRepository adalah implementasi OrderRepository di memori
+type MemoryOrderRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryOrderRepository digunakan untuk membuat OrderRepository berbasis memori
+func NewMemoryOrderRepository(store *MemoryStore) *MemoryOrderRepository {
+	return &MemoryOrderRepository{store: store}
+}
+
+func (r *MemoryOrderRepository) CreateOrder(order model.Order, details []model.OrderDetail, discounts []model.OrderDiscount, cart *model.CartCheckout, email *model.Email) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// item keranjang yang di-checkout harus masih ada dengan jumlah yang sama
+	if cart != nil {
+		if _, ok := r.store.carts[cart.CartID]; !ok {
+			return model.ErrCartNotFound
+		}
+
+		for _, checkedOut := range cart.Items {
+			found := false
+			for _, item := range r.store.cartItems[cart.CartID] {
+				if item.ID == checkedOut.ID && item.Quantity == checkedOut.Quantity {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return model.ErrCartChanged
+			}
+		}
+	}
+
+	// periksa stok produk dan varian, produk tanpa stok dianggap stoknya 0
+	request := model.NewStockRequest(details)
+	productStock := make(map[string]int32)
+	for id := range request.Products {
+		if stock := r.store.products[id].Stock; stock != nil {
+			productStock[id] = *stock
+		}
+	}
+	variantStock := make(map[string]int32)
+	for id := range request.Variants {
+		if stock := r.store.variants[id].Stock; stock != nil {
+			variantStock[id] = *stock
+		}
+	}
+
+	if err := request.Check(productStock, variantStock); err != nil {
+		return err
+	}
+
+	// periksa ulang batas pemakaian promo
+	for _, discount := range discounts {
+		if err := r.store.checkPromoUsage(discount.Code, order.Email); err != nil {
+			return err
+		}
+	}
+
+	// kurangi stok produk dan varian
+	for id, quantity := range request.Products {
+		r.store.adjustProductStock(id, -quantity)
+	}
+	for id, quantity := range request.Variants {
+		r.store.adjustVariantStock(id, -quantity)
+	}
+
+	// hapus item keranjang yang di-checkout
+	if cart != nil {
+		checkedOut := make(map[string]bool)
+		for _, item := range cart.Items {
+			checkedOut[item.ID] = true
+		}
+
+		items := []model.CartItem{}
+		for _, item := range r.store.cartItems[cart.CartID] {
+			if !checkedOut[item.ID] {
+				items = append(items, item)
+			}
+		}
+		r.store.cartItems[cart.CartID] = items
+
+		stored := r.store.carts[cart.CartID]
+		stored.UpdatedAt = order.CreatedAt
+		r.store.carts[cart.CartID] = stored
+	}
+
+	// simpan pesanan beserta detail, potongan, dan riwayat status awal
+	order.Status = model.OrderStatusPending
+	r.store.orders[order.ID] = order
+	r.store.details[order.ID] = append([]model.OrderDetail{}, details...)
+	r.store.discounts[order.ID] = append([]model.OrderDiscount{}, discounts...)
+	r.store.histories[order.ID] = []model.OrderStatusHistory{model.NewStatusHistory(order.ID, nil, model.OrderStatusPending, "", order.CreatedAt)}
+	r.store.appendEmail(email)
+
+	return nil
+}
+
+func (r *MemoryOrderRepository) SelectOrderByID(id string) (model.Order, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	order, ok := r.store.orders[id]
+	if !ok {
+		return model.Order{}, sql.ErrNoRows
+	}
+
+	return order, nil
+}
+
+func (r *MemoryOrderRepository) SelectOrderDetailByOrderID(orderID string) ([]model.OrderDetail, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]model.OrderDetail{}, r.store.details[orderID]...), nil
+}
+
+func (r *MemoryOrderRepository) SelectOrderDiscountByOrderID(orderID string) ([]model.OrderDiscount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]model.OrderDiscount{}, r.store.discounts[orderID]...), nil
+}
+
+func (r *MemoryOrderRepository) SelectOrderStatusHistory(orderID string) ([]model.OrderStatusHistory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]model.OrderStatusHistory{}, r.store.histories[orderID]...), nil
+}
+
+func (r *MemoryOrderRepository) UpdateOrderStatus(id string, confirmation model.Confirm, paidAt time.Time, email *model.Email) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	order, ok := r.store.orders[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	if order.ExpiresAt != nil && paidAt.After(*order.ExpiresAt) {
+		return model.ErrOrderExpired
+	}
+
+	if err := r.store.transitionOrder(id, model.OrderStatusPaid, "", paidAt); err != nil {
+		return err
+	}
+
+	order = r.store.orders[id]
+	order.PaidAt = &paidAt
+	order.PaidBank = &confirmation.Bank
+	order.PaidAccountNumber = &confirmation.AccountNumber
+	r.store.orders[id] = order
+	r.store.appendEmail(email)
+	return nil
+}
+
+func (r *MemoryOrderRepository) CancelUnpaidOrder(id string, reason string, cancelledAt time.Time, email *model.Email) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	order, ok := r.store.orders[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	if err := order.Status.CustomerCancel(); err != nil {
+		return err
+	}
+
+	if err := r.store.transitionOrder(id, model.OrderStatusCancelled, reason, cancelledAt); err != nil {
+		return err
+	}
+
+	r.store.appendEmail(email)
+	return nil
+}
+
+func (r *MemoryOrderRepository) ChangeOrderStatus(id string, to model.OrderStatus, note string, changedAt time.Time, audit *model.AuditLog, email *model.Email) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.store.transitionOrder(id, to, note, changedAt); err != nil {
+		return err
+	}
+
+	r.store.appendAuditLog(audit)
+	r.store.appendEmail(email)
+	return nil
+}
+
+func (r *MemoryOrderRepository) UpdateOrderPasscode(id string, currentHash string, newHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	order, ok := r.store.orders[id]
+	if !ok || order.Passcode == nil || *order.Passcode != currentHash {
+		return model.ErrPasscodeChanged
+	}
+
+	order.Passcode = &newHash
+	r.store.orders[id] = order
+
+	return nil
+}
+
+func (r *MemoryOrderRepository) SelectOrderByCustomerID(customerID string, page int, limit int) ([]model.Order, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// saring pesanan milik pelanggan tanpa passcode, terbaru lebih dulu
+	matched := []model.Order{}
+	for _, order := range r.store.orders {
+		if order.CustomerID != nil && *order.CustomerID == customerID {
+			order.Passcode = nil
+			matched = append(matched, order)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	// ambil halaman yang diminta
+	total := len(matched)
+	start := min((page-1)*limit, total)
+	end := min(start+limit, total)
+
+	return matched[start:end], total, nil
+}
+
+func (r *MemoryOrderRepository) AttachOrderToCustomer(orderID string, customerID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	order, ok := r.store.orders[orderID]
+	if !ok || (order.CustomerID != nil && *order.CustomerID != customerID) {
+		return model.ErrOrderAlreadyAttached
+	}
+
+	order.CustomerID = &customerID
+	r.store.orders[orderID] = order
+
+	return nil
+}
+
+func (r *MemoryOrderRepository) InsertPasscodeResetToken(token model.PasscodeResetToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.resetTokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *MemoryOrderRepository) CountPasscodeResetTokenSince(orderID string, since time.Time) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, token := range r.store.resetTokens {
+		if token.OrderID == orderID && !token.CreatedAt.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *MemoryOrderRepository) RedeemPasscodeResetToken(orderID string, tokenHash string, passcodeHash string, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.resetTokens[tokenHash]
+	if !ok || token.OrderID != orderID || token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		return model.ErrResetTokenInvalid
+	}
+
+	// tandai seluruh token pesanan yang belum dipakai sebagai terpakai
+	for hash, other := range r.store.resetTokens {
+		if other.OrderID == orderID && other.UsedAt == nil {
+			other.UsedAt = &now
+			r.store.resetTokens[hash] = other
+		}
+	}
+
+	order := r.store.orders[orderID]
+	order.Passcode = &passcodeHash
+	r.store.orders[orderID] = order
+
+	return nil
+}
+
+func (r *MemoryOrderRepository) ApplyPromoCode(code string, email string, details []model.OrderDetail, shippingFee int64, now time.Time) (model.OrderDiscount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// ambil data kode promo
+	promo, ok := r.store.promos[model.NormalizePromoCode(code)]
+	if !ok {
+		return model.OrderDiscount{}, model.ErrPromoNotFound
+	}
+
+	// ambil produk yang memenuhi cakupan promo, promo tanpa cakupan berlaku untuk semua produk
+	scoped := len(promo.ProductIDs) > 0 || len(promo.CategoryIDs) > 0
+	scopeProducts := make(map[string]bool)
+	for _, id := range promo.ProductIDs {
+		scopeProducts[id] = true
+	}
+	scopeCategories := r.store.categoryTree(promo.CategoryIDs)
+
+	eligible := make(map[string]bool)
+	for _, detail := range details {
+		if !scoped || scopeProducts[detail.ProductID] || r.store.inCategories(detail.ProductID, scopeCategories) {
+			eligible[detail.ProductID] = true
+		}
+	}
+
+	// validasi kode promo dan hitung potongannya
+	discount, err := promo.Evaluate(details, eligible, shippingFee, now)
+	if err != nil {
+		return model.OrderDiscount{}, err
+	}
+
+	// periksa batas pemakaian
+	if err := r.store.checkPromoUsage(promo.Code, email); err != nil {
+		return model.OrderDiscount{}, err
+	}
+
+	return discount, nil
+}
+
+// MemoryAdminRepository adalah implementasi AdminRepository di memori
+type MemoryAdminRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryAdminRepository digunakan untuk membuat AdminRepository berbasis memori
+func NewMemoryAdminRepository(store *MemoryStore) *MemoryAdminRepository {
+	return &MemoryAdminRepository{store: store}
+}
+
+func (r *MemoryAdminRepository) SelectAdminUser() ([]model.AdminUser, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	admins := []model.AdminUser{}
+	for _, admin := range r.store.admins {
+		admins = append(admins, copyAdminUser(admin))
+	}
+
+	sort.Slice(admins, func(i, j int) bool { return admins[i].Email < admins[j].Email })
+	return admins, nil
+}
+
+func (r *MemoryAdminRepository) SelectAdminUserByID(id string) (model.AdminUser, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	admin, ok := r.store.admins[id]
+	if !ok {
+		return model.AdminUser{}, sql.ErrNoRows
+	}
+
+	return copyAdminUser(admin), nil
+}
+
+func (r *MemoryAdminRepository) SelectAdminUserByEmail(email string) (model.AdminUser, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, admin := range r.store.admins {
+		if strings.EqualFold(admin.Email, email) {
+			return copyAdminUser(admin), nil
+		}
+	}
+
+	return model.AdminUser{}, sql.ErrNoRows
+}
+
+func (r *MemoryAdminRepository) InsertAdminUser(admin model.AdminUser, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.adminEmailTaken(admin.Email, admin.ID) {
+		return model.ErrDuplicateAdminEmail
+	}
+
+	if admin.IsActive == nil {
+		isActive := true
+		admin.IsActive = &isActive
+	}
+
+	// password hanya disimpan dalam bentuk hash
+	admin.Password = ""
+	r.store.admins[admin.ID] = copyAdminUser(admin)
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+func (r *MemoryAdminRepository) UpdateAdminUser(admin model.AdminUser, audit *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.admins[admin.ID]
+	if !ok {
+		return missingRow(audit)
+	}
+
+	if r.store.adminEmailTaken(admin.Email, admin.ID) {
+		return model.ErrDuplicateAdminEmail
+	}
+
+	// cegah super admin aktif terakhir kehilangan aksesnya
+	superAdmins := []string{}
+	for _, other := range r.store.admins {
+		if other.Role == model.AdminRoleSuperAdmin && other.IsActive != nil && *other.IsActive {
+			superAdmins = append(superAdmins, other.ID)
+		}
+	}
+	if err := admin.CheckLastSuperAdmin(superAdmins); err != nil {
+		return err
+	}
+
+	existing.Email = admin.Email
+	existing.PasswordHash = admin.PasswordHash
+	existing.Role = admin.Role
+	if admin.IsActive != nil {
+		isActive := *admin.IsActive
+		existing.IsActive = &isActive
+	}
+
+	r.store.admins[admin.ID] = existing
+	r.store.appendAuditLog(audit)
+	return nil
+}
+
+// MemoryCustomerRepository adalah implementasi CustomerRepository di memori
+type MemoryCustomerRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryCustomerRepository digunakan untuk membuat CustomerRepository berbasis memori
+func NewMemoryCustomerRepository(store *MemoryStore) *MemoryCustomerRepository {
+	return &MemoryCustomerRepository{store: store}
+}
+
+func (r *MemoryCustomerRepository) SelectCustomerByID(id string) (model.Customer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	customer, ok := r.store.customers[id]
+	if !ok {
+		return model.Customer{}, sql.ErrNoRows
+	}
+
+	return customer, nil
+}
+
+func (r *MemoryCustomerRepository) SelectCustomerByEmail(email string) (model.Customer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, customer := range r.store.customers {
+		if strings.EqualFold(customer.Email, email) {
+			return customer, nil
+		}
+	}
+
+	return model.Customer{}, sql.ErrNoRows
+}
+
+func (r *MemoryCustomerRepository) InsertCustomer(customer model.Customer) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.customers {
+		if strings.EqualFold(existing.Email, customer.Email) {
+			return model.ErrDuplicateCustomerEmail
+		}
+	}
+
+	r.store.customers[customer.ID] = customer
+	return nil
+}
+
+// MemoryAuditRepository adalah implementasi AuditRepository di memori
+type MemoryAuditRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryAuditRepository digunakan untuk membuat AuditRepository berbasis memori
+func NewMemoryAuditRepository(store *MemoryStore) *MemoryAuditRepository {
+	return &MemoryAuditRepository{store: store}
+}
+
+func (r *MemoryAuditRepository) SelectAuditLog(filter model.AuditFilter) ([]model.AuditLog, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// saring audit log sesuai filter, terbaru lebih dulu
+	matched := []model.AuditLog{}
+	for i := len(r.store.auditLogs) - 1; i >= 0; i-- {
+		log := r.store.auditLogs[i]
+		if filter.ActorID != "" && (log.ActorID == nil || *log.ActorID != filter.ActorID) {
+			continue
+		}
+		if filter.EntityType != "" && log.EntityType != filter.EntityType {
+			continue
+		}
+		if filter.EntityID != "" && log.EntityID != filter.EntityID {
+			continue
+		}
+		if filter.From != nil && log.CreatedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !log.CreatedAt.Before(*filter.To) {
+			continue
+		}
+		matched = append(matched, log)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	// ambil halaman yang diminta
+	total := len(matched)
+	start := min((filter.Page-1)*filter.Limit, total)
+	end := min(start+filter.Limit, total)
+
+	return matched[start:end], total, nil
+}
+
+// MemoryPasscodeAttemptRepository adalah implementasi PasscodeAttemptRepository di memori
+type MemoryPasscodeAttemptRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryPasscodeAttemptRepository digunakan untuk membuat PasscodeAttemptRepository berbasis memori
+func NewMemoryPasscodeAttemptRepository(store *MemoryStore) *MemoryPasscodeAttemptRepository {
+	return &MemoryPasscodeAttemptRepository{store: store}
+}
+
+func (r *MemoryPasscodeAttemptRepository) ReservePasscodeAttempt(keys []model.AttemptKey, policy model.PasscodePolicy, now time.Time) (model.PasscodeReservation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	attempts := make([]model.PasscodeAttempt, len(keys))
+	for i, key := range keys {
+		attempts[i] = r.store.passcodeAttempts[key]
+	}
+
+	reservation := policy.Reserve(keys, attempts, now)
+	for i, attempt := range reservation.Reserved {
+		r.store.passcodeAttempts[keys[i]] = attempt
+	}
+
+	return reservation, nil
+}
+
+func (r *MemoryPasscodeAttemptRepository) ReleasePasscodeAttempt(reservation model.PasscodeReservation) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i, key := range reservation.Keys {
+		current, exists := r.store.passcodeAttempts[key]
+		if !exists {
+			continue
+		}
+
+		attempt, remove, ok := reservation.Release(i, current)
+		switch {
+		case remove:
+			delete(r.store.passcodeAttempts, key)
+		case ok:
+			r.store.passcodeAttempts[key] = attempt
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryPasscodeAttemptRepository) ResetPasscodeFailures(key model.AttemptKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.passcodeAttempts, key)
+	return nil
+}
+
+// MemoryEmailRepository adalah implementasi EmailRepository di memori
+type MemoryEmailRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryEmailRepository digunakan untuk membuat EmailRepository berbasis memori
+func NewMemoryEmailRepository(store *MemoryStore) *MemoryEmailRepository {
+	return &MemoryEmailRepository{store: store}
+}
+
+func (r *MemoryEmailRepository) InsertEmail(email model.Email) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.emails = append(r.store.emails, email)
+	return nil
+}
+
+// Emails digunakan untuk mengambil seluruh email di antrean, digunakan untuk pengujian
+func (s *MemoryStore) Emails() []model.Email {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]model.Email{}, s.emails...)
+}
+
+// MemoryCartRepository adalah implementasi CartRepository di memori
+type MemoryCartRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryCartRepository digunakan untuk membuat CartRepository berbasis memori
+func NewMemoryCartRepository(store *MemoryStore) *MemoryCartRepository {
+	return &MemoryCartRepository{store: store}
+}
+
+func (r *MemoryCartRepository) InsertCart(cart model.Cart) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// satu pelanggan hanya memiliki satu keranjang
+	if cart.CustomerID != nil {
+		for _, existing := range r.store.carts {
+			if existing.CustomerID != nil && *existing.CustomerID == *cart.CustomerID {
+				return model.ErrCustomerCartExists
+			}
+		}
+	}
+
+	// token asli tidak disimpan, sama seperti implementasi PostgreSQL
+	cart.Items = nil
+	cart.Token = ""
+	r.store.carts[cart.ID] = cart
+	return nil
+}
+
+func (r *MemoryCartRepository) SelectCartByID(id string) (model.Cart, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	cart, ok := r.store.carts[id]
+	if !ok {
+		return model.Cart{}, sql.ErrNoRows
+	}
+
+	return cart, nil
+}
+
+func (r *MemoryCartRepository) SelectCartByCustomerID(customerID string) (model.Cart, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, cart := range r.store.carts {
+		if cart.CustomerID != nil && *cart.CustomerID == customerID {
+			return cart, nil
+		}
+	}
+
+	return model.Cart{}, sql.ErrNoRows
+}
+
+func (r *MemoryCartRepository) SelectCartItems(cartID string) ([]model.CartItem, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]model.CartItem{}, r.store.cartItems[cartID]...), nil
+}
+
+func (r *MemoryCartRepository) UpsertCartItem(item model.CartItem) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	cart, ok := r.store.carts[item.CartID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	// tambahkan jumlah jika produk/varian yang sama sudah ada
+	items := r.store.cartItems[item.CartID]
+	found := false
+	for i, existing := range items {
+		if existing.ProductID == item.ProductID && sameVariant(existing.VariantID, item.VariantID) {
+			items[i].Quantity = int32(min(int64(existing.Quantity)+int64(item.Quantity), math.MaxInt32))
+			found = true
+			break
+		}
+	}
+	if !found {
+		items = append(items, item)
+	}
+
+	r.store.cartItems[item.CartID] = items
+	cart.UpdatedAt = item.AddedAt
+	r.store.carts[item.CartID] = cart
+
+	return nil
+}
+
+func (r *MemoryCartRepository) UpdateCartItemQuantity(cartID string, itemID string, quantity int32, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	items := r.store.cartItems[cartID]
+	for i, item := range items {
+		if item.ID == itemID {
+			items[i].Quantity = quantity
+			r.touchCart(cartID, now)
+			return nil
+		}
+	}
+
+	return sql.ErrNoRows
+}
+
+func (r *MemoryCartRepository) DeleteCartItem(cartID string, itemID string, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	items := []model.CartItem{}
+	for _, item := range r.store.cartItems[cartID] {
+		if item.ID != itemID {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == len(r.store.cartItems[cartID]) {
+		return sql.ErrNoRows
+	}
+
+	r.store.cartItems[cartID] = items
+	r.touchCart(cartID, now)
+
+	return nil
+}
+
+// touchCart digunakan untuk memperbarui waktu perubahan terakhir keranjang (mutex harus sudah dikunci)
+func (r *MemoryCartRepository) touchCart(cartID string, now time.Time) {
+	if cart, ok := r.store.carts[cartID]; ok {
+		cart.UpdatedAt = now
+		r.store.carts[cartID] = cart
+	}
+}
+
+// sameVariant digunakan untuk membandingkan dua ID varian yang boleh kosong
+func sameVariant(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return *a == *b
+}
+
+// missingRow digunakan untuk meniru perilaku PostgreSQL saat data yang diubah tidak ada: sql.ErrNoRows jika
+// perubahan dicatat di audit log, selain itu tidak ada yang dilakukan
+func missingRow(audit *model.AuditLog) error {
+	if audit != nil {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// skuTaken digunakan untuk memeriksa apakah kode SKU sudah dipakai varian lain (mutex harus sudah dikunci)
+func (s *MemoryStore) skuTaken(sku string, exceptID string) bool {
+	for _, variant := range s.variants {
+		if variant.SKU == sku && variant.ID != exceptID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// adminEmailTaken digunakan untuk memeriksa apakah email sudah dipakai admin lain (mutex harus sudah dikunci)
+func (s *MemoryStore) adminEmailTaken(email string, exceptID string) bool {
+	for _, admin := range s.admins {
+		if strings.EqualFold(admin.Email, email) && admin.ID != exceptID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// promoByID digunakan untuk mengambil kode promo berdasarkan ID (mutex harus sudah dikunci)
+func (s *MemoryStore) promoByID(id string) (model.PromoCode, bool) {
+	for _, promo := range s.promos {
+		if promo.ID == id {
+			return promo, true
+		}
+	}
+
+	return model.PromoCode{}, false
+}
+
+// promoWithUsage digunakan untuk melengkapi kode promo dengan jumlah pemakaian dan cakupannya (mutex harus sudah dikunci)
+func (s *MemoryStore) promoWithUsage(promo model.PromoCode) model.PromoCode {
+	promo = copyPromo(promo)
+	promo.UsedCount = 0
+	for orderID, discounts := range s.discounts {
+		if s.orders[orderID].Status.IsVoid() {
+			continue
+		}
+
+		for _, discount := range discounts {
+			if discount.PromoCodeID == promo.ID {
+				promo.UsedCount++
+			}
+		}
+	}
+
+	if promo.ProductIDs == nil {
+		promo.ProductIDs = []string{}
+	}
+	if promo.CategoryIDs == nil {
+		promo.CategoryIDs = []string{}
+	}
+	sort.Strings(promo.ProductIDs)
+	sort.Strings(promo.CategoryIDs)
+
+	return promo
+}
+
+// checkPromoScope digunakan untuk memastikan produk dan kategori cakupan promo ada (mutex harus sudah dikunci)
+func (s *MemoryStore) checkPromoScope(promo model.PromoCode) error {
+	for _, id := range promo.ProductIDs {
+		if _, ok := s.products[id]; !ok {
+			return model.ErrPromoScopeNotFound
+		}
+	}
+
+	for _, id := range promo.CategoryIDs {
+		if _, ok := s.categories[id]; !ok {
+			return model.ErrPromoScopeNotFound
+		}
+	}
+
+	return nil
+}
+
+// validCategoryIDs digunakan untuk memastikan seluruh kategori ada dan menghapus ID yang duplikat,
+// nil berarti kategori produk tidak diubah (mutex harus sudah dikunci)
+func (s *MemoryStore) validCategoryIDs(categoryIDs []string) ([]string, error) {
+	if categoryIDs == nil {
+		return nil, nil
+	}
+
+	ids := []string{}
+	seen := make(map[string]bool)
+	for _, id := range categoryIDs {
+		if _, ok := s.categories[id]; !ok {
+			return nil, model.ErrCategoryNotFound
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// appendAuditLog digunakan untuk menyimpan audit log bersama perubahan yang dicatat, nil berarti tidak dicatat (mutex harus sudah dikunci)
+func (s *MemoryStore) appendAuditLog(log *model.AuditLog) {
+	if log != nil {
+		s.auditLogs = append(s.auditLogs, *log)
+	}
+}
+
+// appendEmail digunakan untuk memasukkan email ke antrean bersama perubahan yang diberitahukan, nil berarti tanpa email (mutex harus sudah dikunci)
+func (s *MemoryStore) appendEmail(email *model.Email) {
+	if email != nil {
+		s.emails = append(s.emails, *email)
+	}
+}
+
+// transitionOrder digunakan untuk memindahkan status pesanan sesuai state machine (mutex harus sudah dikunci)
+func (s *MemoryStore) transitionOrder(id string, to model.OrderStatus, note string, changedAt time.Time) error {
+	order, ok := s.orders[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	from := order.Status
+	if err := from.Transition(to); err != nil {
+		return err
+	}
+
+	// catat waktu dan alasan pembatalan
+	order.Status = to
+	if to == model.OrderStatusCancelled {
+		order.CancelledAt = &changedAt
+		if note != "" {
+			order.CancelReason = &note
+		}
+	}
+	s.orders[id] = order
+
+	// kembalikan stok produk jika pesanan dibatalkan atau kedaluwarsa
+	if to.IsVoid() {
+		request := model.NewStockRequest(s.details[id])
+		for productID, quantity := range request.Products {
+			s.adjustProductStock(productID, quantity)
+		}
+		for variantID, quantity := range request.Variants {
+			s.adjustVariantStock(variantID, quantity)
+		}
+	}
+
+	// simpan riwayat perubahan status
+	s.histories[id] = append(s.histories[id], model.NewStatusHistory(id, &from, to, note, changedAt))
+
+	return nil
+}
+
+// checkPromoUsage digunakan untuk memeriksa batas pemakaian kode promo (mutex harus sudah dikunci)
+func (s *MemoryStore) checkPromoUsage(code string, email string) error {
+	promo, ok := s.promos[code]
+	if !ok {
+		return model.ErrPromoNotFound
+	}
+
+	var usedByEmail int32
+	for orderID, discounts := range s.discounts {
+		order := s.orders[orderID]
+		if order.Status.IsVoid() || !strings.EqualFold(order.Email, email) {
+			continue
+		}
+
+		for _, discount := range discounts {
+			if discount.PromoCodeID == promo.ID {
+				usedByEmail++
+			}
+		}
+	}
+
+	return s.promoWithUsage(promo).CheckUsage(usedByEmail)
+}
+
+// categoryTree digunakan untuk mengambil ID kategori beserta seluruh turunannya (mutex harus sudah dikunci)
+func (s *MemoryStore) categoryTree(roots []string) map[string]bool {
+	tree := make(map[string]bool)
+	for _, id := range roots {
+		tree[id] = true
+	}
+
+	// tambahkan turunan hingga tidak ada kategori baru
+	for changed := true; changed; {
+		changed = false
+		for _, category := range s.categories {
+			if category.ParentID != nil && tree[*category.ParentID] && !tree[category.ID] {
+				tree[category.ID] = true
+				changed = true
+			}
+		}
+	}
+
+	return tree
+}
+
+// inCategories digunakan untuk memeriksa apakah produk berada di salah satu kategori (mutex harus sudah dikunci)
+func (s *MemoryStore) inCategories(productID string, categoryIDs map[string]bool) bool {
+	for _, id := range s.productCategories[productID] {
+		if categoryIDs[id] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// adjustProductStock digunakan untuk menambah atau mengurangi stok produk (mutex harus sudah dikunci)
+func (s *MemoryStore) adjustProductStock(id string, delta int32) {
+	product, ok := s.products[id]
+	if !ok {
+		return
+	}
+
+	stock := delta
+	if product.Stock != nil {
+		stock += *product.Stock
+	}
+	product.Stock = &stock
+	s.products[id] = product
+}
+
+// adjustVariantStock digunakan untuk menambah atau mengurangi stok varian (mutex harus sudah dikunci)
+func (s *MemoryStore) adjustVariantStock(id string, delta int32) {
+	variant, ok := s.variants[id]
+	if !ok {
+		return
+	}
+
+	stock := delta
+	if variant.Stock != nil {
+		stock += *variant.Stock
+	}
+	variant.Stock = &stock
+	s.variants[id] = variant
+}
+
+// copyProduct digunakan untuk menyalin produk agar data di penyimpanan tidak ikut berubah
+func (s *MemoryStore) copyProduct(product model.Product) model.Product {
+	product.Stock = copyInt32(product.Stock)
+	product.MaxOrderQuantity = copyInt32(product.MaxOrderQuantity)
+	product.IsDeleted = nil
+	return product
+}
+
+// copyVariant digunakan untuk menyalin varian agar data di penyimpanan tidak ikut berubah
+func copyVariant(variant model.ProductVariant) model.ProductVariant {
+	variant.Stock = copyInt32(variant.Stock)
+	return variant
+}
+
+// copyPromo digunakan untuk menyalin kode promo agar data di penyimpanan tidak ikut berubah
+func copyPromo(promo model.PromoCode) model.PromoCode {
+	promo.ProductIDs = slices.Clone(promo.ProductIDs)
+	promo.CategoryIDs = slices.Clone(promo.CategoryIDs)
+	slices.Sort(promo.ProductIDs)
+	slices.Sort(promo.CategoryIDs)
+	promo.ProductIDs = slices.Compact(promo.ProductIDs)
+	promo.CategoryIDs = slices.Compact(promo.CategoryIDs)
+	return promo
+}
+
+// copyAdminUser digunakan untuk menyalin admin agar data di penyimpanan tidak ikut berubah
+func copyAdminUser(admin model.AdminUser) model.AdminUser {
+	if admin.IsActive != nil {
+		isActive := *admin.IsActive
+		admin.IsActive = &isActive
+	}
+
+	return admin
+}
+
+// sortedPromoCodes digunakan untuk mengambil kode promo secara berurutan
+func sortedPromoCodes(promos map[string]model.PromoCode) []string {
+	codes := make([]string, 0, len(promos))
+	for code := range promos {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// copyInt32 digunakan untuk menyalin nilai pointer int32
+func copyInt32(value *int32) *int32 {
+	if value == nil {
+		return nil
+	}
+
+	copied := *value
+	return &copied
+}
+
+// MemoryIdempotencyRepository adalah implementasi IdempotencyRepository di memori
+type MemoryIdempotencyRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryIdempotencyRepository digunakan untuk membuat IdempotencyRepository berbasis memori
+func NewMemoryIdempotencyRepository(store *MemoryStore) *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{store: store}
+}
+
+func (r *MemoryIdempotencyRepository) ReserveIdempotencyKey(key model.IdempotencyKey) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// kunci yang kedaluwarsa atau macet di tengah proses boleh dipakai ulang
+	existing, ok := r.store.idempotencyKeys[key.KeyHash]
+	if ok && existing.ExpiresAt.After(key.CreatedAt) && (existing.StatusCode != nil || existing.LockedUntil.After(key.CreatedAt)) {
+		return false, nil
+	}
+
+	key.StatusCode = nil
+	key.ContentType = ""
+	key.Response = nil
+	r.store.idempotencyKeys[key.KeyHash] = key
+	return true, nil
+}
+
+func (r *MemoryIdempotencyRepository) SelectIdempotencyKey(keyHash string) (model.IdempotencyKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	key, ok := r.store.idempotencyKeys[keyHash]
+	if !ok {
+		return model.IdempotencyKey{}, sql.ErrNoRows
+	}
+
+	return key, nil
+}
+
+func (r *MemoryIdempotencyRepository) ExtendIdempotencyKey(keyHash string, fingerprint string, lockedUntil time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.idempotencyKeys[keyHash]
+	if ok && key.Fingerprint == fingerprint && key.StatusCode == nil {
+		key.LockedUntil = lockedUntil
+		r.store.idempotencyKeys[keyHash] = key
+	}
+
+	return nil
+}
+
+func (r *MemoryIdempotencyRepository) CompleteIdempotencyKey(keyHash string, fingerprint string, statusCode int, contentType string, response []byte) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.idempotencyKeys[keyHash]
+	if !ok || key.Fingerprint != fingerprint || key.StatusCode != nil {
+		return nil
+	}
+
+	key.StatusCode = &statusCode
+	key.ContentType = contentType
+	key.Response = append([]byte{}, response...)
+	r.store.idempotencyKeys[keyHash] = key
+	return nil
+}
+
+func (r *MemoryIdempotencyRepository) ReleaseIdempotencyKey(keyHash string, fingerprint string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.idempotencyKeys[keyHash]
+	if ok && key.Fingerprint == fingerprint && key.StatusCode == nil {
+		delete(r.store.idempotencyKeys, keyHash)
+	}
+
+	return nil
+}
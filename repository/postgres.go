@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+)
+
+// pastikan implementasi PostgreSQL memenuhi kontrak repository
+var (
+	_ ProductRepository         = (*PostgresProductRepository)(nil)
+	_ CategoryRepository        = (*PostgresCategoryRepository)(nil)
+	_ PromoRepository           = (*PostgresPromoRepository)(nil)
+	_ OrderRepository           = (*PostgresOrderRepository)(nil)
+	_ AdminRepository           = (*PostgresAdminRepository)(nil)
+	_ CustomerRepository        = (*PostgresCustomerRepository)(nil)
+	_ AuditRepository           = (*PostgresAuditRepository)(nil)
+	_ PasscodeAttemptRepository = (*PostgresPasscodeAttemptRepository)(nil)
+	_ EmailRepository           = (*PostgresEmailRepository)(nil)
+	_ CartRepository            = (*PostgresCartRepository)(nil)
+	_ IdempotencyRepository     = (*PostgresIdempotencyRepository)(nil)
+)
+
+// PostgresProductRepository adalah implementasi ProductRepository menggunakan PostgreSQL
+type PostgresProductRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresProductRepository digunakan untuk membuat ProductRepository berbasis PostgreSQL
+func NewPostgresProductRepository(db *sql.DB) *PostgresProductRepository {
+	return &PostgresProductRepository{db: db}
+}
+
+func (r *PostgresProductRepository) SelectProduct(filter model.ProductFilter) ([]model.Product, int, error) {
+	return model.SelectProduct(r.db, filter)
+}
+
+func (r *PostgresProductRepository) SelectProductByID(id string) (model.Product, error) {
+	return model.SelectProductByID(r.db, id)
+}
+
+func (r *PostgresProductRepository) SelectProductIn(ids []string) ([]model.Product, error) {
+	return model.SelectProductIn(r.db, ids)
+}
+
+func (r *PostgresProductRepository) SearchProducts(keyword string, limit int) ([]model.ProductSearchResult, error) {
+	return model.SearchProducts(r.db, keyword, limit)
+}
+
+func (r *PostgresProductRepository) InsertProduct(product model.Product, audit *model.AuditLog) error {
+	return model.InsertProduct(r.db, product, audit)
+}
+
+func (r *PostgresProductRepository) UpdateProduct(product model.Product, audit *model.AuditLog) error {
+	return model.UpdateProduct(r.db, product, audit)
+}
+
+func (r *PostgresProductRepository) DeleteProduct(id string, audit *model.AuditLog) error {
+	return model.DeleteProduct(r.db, id, audit)
+}
+
+func (r *PostgresProductRepository) SelectProductCategoryIDs(productID string) ([]string, error) {
+	return model.SelectProductCategoryIDs(r.db, productID)
+}
+
+func (r *PostgresProductRepository) SelectVariantByProductID(productID string) ([]model.ProductVariant, error) {
+	return model.SelectVariantByProductID(r.db, productID)
+}
+
+func (r *PostgresProductRepository) SelectVariantIn(ids []string) ([]model.ProductVariant, error) {
+	return model.SelectVariantIn(r.db, ids)
+}
+
+func (r *PostgresProductRepository) SelectProductIDsWithVariants(productIDs []string) (map[string]bool, error) {
+	return model.SelectProductIDsWithVariants(r.db, productIDs)
+}
+
+func (r *PostgresProductRepository) SelectVariantByID(productID string, id string) (model.ProductVariant, error) {
+	return model.SelectVariantByID(r.db, productID, id)
+}
+
+func (r *PostgresProductRepository) InsertVariant(variant model.ProductVariant, audit *model.AuditLog) error {
+	return model.InsertVariant(r.db, variant, audit)
+}
+
+func (r *PostgresProductRepository) UpdateVariant(variant model.ProductVariant, audit *model.AuditLog) error {
+	return model.UpdateVariant(r.db, variant, audit)
+}
+
+func (r *PostgresProductRepository) DeleteVariant(productID string, id string, audit *model.AuditLog) error {
+	return model.DeleteVariant(r.db, productID, id, audit)
+}
+
+// PostgresCategoryRepository adalah implementasi CategoryRepository menggunakan PostgreSQL
+type PostgresCategoryRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresCategoryRepository digunakan untuk membuat CategoryRepository berbasis PostgreSQL
+func NewPostgresCategoryRepository(db *sql.DB) *PostgresCategoryRepository {
+	return &PostgresCategoryRepository{db: db}
+}
+
+func (r *PostgresCategoryRepository) SelectCategory() ([]model.Category, error) {
+	return model.SelectCategory(r.db)
+}
+
+func (r *PostgresCategoryRepository) SelectCategoryByID(id string) (model.Category, error) {
+	return model.SelectCategoryByID(r.db, id)
+}
+
+func (r *PostgresCategoryRepository) InsertCategory(category model.Category, audit *model.AuditLog) error {
+	return model.InsertCategory(r.db, category, audit)
+}
+
+func (r *PostgresCategoryRepository) UpdateCategory(category model.Category, audit *model.AuditLog) error {
+	return model.UpdateCategory(r.db, category, audit)
+}
+
+func (r *PostgresCategoryRepository) DeleteCategory(id string, audit *model.AuditLog) error {
+	return model.DeleteCategory(r.db, id, audit)
+}
+
+// PostgresPromoRepository adalah implementasi PromoRepository menggunakan PostgreSQL
+type PostgresPromoRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresPromoRepository digunakan untuk membuat PromoRepository berbasis PostgreSQL
+func NewPostgresPromoRepository(db *sql.DB) *PostgresPromoRepository {
+	return &PostgresPromoRepository{db: db}
+}
+
+func (r *PostgresPromoRepository) SelectPromoCode() ([]model.PromoCode, error) {
+	return model.SelectPromoCode(r.db)
+}
+
+func (r *PostgresPromoRepository) SelectPromoCodeByID(id string) (model.PromoCode, error) {
+	return model.SelectPromoCodeByID(r.db, id)
+}
+
+func (r *PostgresPromoRepository) InsertPromoCode(promo model.PromoCode, audit *model.AuditLog) error {
+	return model.InsertPromoCode(r.db, promo, audit)
+}
+
+func (r *PostgresPromoRepository) UpdatePromoCode(promo model.PromoCode, audit *model.AuditLog) error {
+	return model.UpdatePromoCode(r.db, promo, audit)
+}
+
+func (r *PostgresPromoRepository) DeactivatePromoCode(id string, audit *model.AuditLog) error {
+	return model.DeactivatePromoCode(r.db, id, audit)
+}
+
+// PostgresOrderRepository adalah implementasi OrderRepository menggunakan PostgreSQL
+type PostgresOrderRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresOrderRepository digunakan untuk membuat OrderRepository berbasis PostgreSQL
+func NewPostgresOrderRepository(db *sql.DB) *PostgresOrderRepository {
+	return &PostgresOrderRepository{db: db}
+}
+
+func (r *PostgresOrderRepository) CreateOrder(order model.Order, details []model.OrderDetail, discounts []model.OrderDiscount, cart *model.CartCheckout, email *model.Email) error {
+	return model.CreateOrder(r.db, order, details, discounts, cart, email)
+}
+
+func (r *PostgresOrderRepository) SelectOrderByID(id string) (model.Order, error) {
+	return model.SelectOrderByID(r.db, id)
+}
+
+func (r *PostgresOrderRepository) SelectOrderDetailByOrderID(orderID string) ([]model.OrderDetail, error) {
+	return model.SelectOrderDetailByOrderID(r.db, orderID)
+}
+
+func (r *PostgresOrderRepository) SelectOrderDiscountByOrderID(orderID string) ([]model.OrderDiscount, error) {
+	return model.SelectOrderDiscountByOrderID(r.db, orderID)
+}
+
+func (r *PostgresOrderRepository) SelectOrderStatusHistory(orderID string) ([]model.OrderStatusHistory, error) {
+	return model.SelectOrderStatusHistory(r.db, orderID)
+}
+
+func (r *PostgresOrderRepository) UpdateOrderStatus(id string, confirmation model.Confirm, paidAt time.Time, email *model.Email) error {
+	return model.UpdateOrderStatus(r.db, id, confirmation, paidAt, email)
+}
+
+func (r *PostgresOrderRepository) CancelUnpaidOrder(id string, reason string, cancelledAt time.Time, email *model.Email) error {
+	return model.CancelUnpaidOrder(r.db, id, reason, cancelledAt, email)
+}
+
+func (r *PostgresOrderRepository) ChangeOrderStatus(id string, to model.OrderStatus, note string, changedAt time.Time, audit *model.AuditLog, email *model.Email) error {
+	return model.ChangeOrderStatus(r.db, id, to, note, changedAt, audit, email)
+}
+
+func (r *PostgresOrderRepository) UpdateOrderPasscode(id string, currentHash string, newHash string) error {
+	return model.UpdateOrderPasscode(r.db, id, currentHash, newHash)
+}
+
+func (r *PostgresOrderRepository) SelectOrderByCustomerID(customerID string, page int, limit int) ([]model.Order, int, error) {
+	return model.SelectOrderByCustomerID(r.db, customerID, page, limit)
+}
+
+func (r *PostgresOrderRepository) AttachOrderToCustomer(orderID string, customerID string) error {
+	return model.AttachOrderToCustomer(r.db, orderID, customerID)
+}
+
+func (r *PostgresOrderRepository) InsertPasscodeResetToken(token model.PasscodeResetToken) error {
+	return model.InsertPasscodeResetToken(r.db, token)
+}
+
+func (r *PostgresOrderRepository) CountPasscodeResetTokenSince(orderID string, since time.Time) (int, error) {
+	return model.CountPasscodeResetTokenSince(r.db, orderID, since)
+}
+
+func (r *PostgresOrderRepository) RedeemPasscodeResetToken(orderID string, tokenHash string, passcodeHash string, now time.Time) error {
+	return model.RedeemPasscodeResetToken(r.db, orderID, tokenHash, passcodeHash, now)
+}
+
+func (r *PostgresOrderRepository) ApplyPromoCode(code string, email string, details []model.OrderDetail, shippingFee int64, now time.Time) (model.OrderDiscount, error) {
+	return model.ApplyPromoCode(r.db, code, email, details, shippingFee, now)
+}
+
+// PostgresAdminRepository adalah implementasi AdminRepository menggunakan PostgreSQL
+type PostgresAdminRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAdminRepository digunakan untuk membuat AdminRepository berbasis PostgreSQL
+func NewPostgresAdminRepository(db *sql.DB) *PostgresAdminRepository {
+	return &PostgresAdminRepository{db: db}
+}
+
+func (r *PostgresAdminRepository) SelectAdminUser() ([]model.AdminUser, error) {
+	return model.SelectAdminUser(r.db)
+}
+
+func (r *PostgresAdminRepository) SelectAdminUserByID(id string) (model.AdminUser, error) {
+	return model.SelectAdminUserByID(r.db, id)
+}
+
+func (r *PostgresAdminRepository) SelectAdminUserByEmail(email string) (model.AdminUser, error) {
+	return model.SelectAdminUserByEmail(r.db, email)
+}
+
+func (r *PostgresAdminRepository) InsertAdminUser(admin model.AdminUser, audit *model.AuditLog) error {
+	return model.InsertAdminUser(r.db, admin, audit)
+}
+
+func (r *PostgresAdminRepository) UpdateAdminUser(admin model.AdminUser, audit *model.AuditLog) error {
+	return model.UpdateAdminUser(r.db, admin, audit)
+}
+
+// PostgresCustomerRepository adalah implementasi CustomerRepository menggunakan PostgreSQL
+type PostgresCustomerRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresCustomerRepository digunakan untuk membuat CustomerRepository berbasis PostgreSQL
+func NewPostgresCustomerRepository(db *sql.DB) *PostgresCustomerRepository {
+	return &PostgresCustomerRepository{db: db}
+}
+
+func (r *PostgresCustomerRepository) SelectCustomerByID(id string) (model.Customer, error) {
+	return model.SelectCustomerByID(r.db, id)
+}
+
+func (r *PostgresCustomerRepository) SelectCustomerByEmail(email string) (model.Customer, error) {
+	return model.SelectCustomerByEmail(r.db, email)
+}
+
+func (r *PostgresCustomerRepository) InsertCustomer(customer model.Customer) error {
+	return model.InsertCustomer(r.db, customer)
+}
+
+// PostgresAuditRepository adalah implementasi AuditRepository menggunakan PostgreSQL
+type PostgresAuditRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAuditRepository digunakan untuk membuat AuditRepository berbasis PostgreSQL
+func NewPostgresAuditRepository(db *sql.DB) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+func (r *PostgresAuditRepository) SelectAuditLog(filter model.AuditFilter) ([]model.AuditLog, int, error) {
+	return model.SelectAuditLog(r.db, filter)
+}
+
+// PostgresPasscodeAttemptRepository adalah implementasi PasscodeAttemptRepository menggunakan PostgreSQL
+type PostgresPasscodeAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresPasscodeAttemptRepository digunakan untuk membuat PasscodeAttemptRepository berbasis PostgreSQL
+func NewPostgresPasscodeAttemptRepository(db *sql.DB) *PostgresPasscodeAttemptRepository {
+	return &PostgresPasscodeAttemptRepository{db: db}
+}
+
+func (r *PostgresPasscodeAttemptRepository) ReservePasscodeAttempt(keys []model.AttemptKey, policy model.PasscodePolicy, now time.Time) (model.PasscodeReservation, error) {
+	return model.ReservePasscodeAttempt(r.db, keys, policy, now)
+}
+
+func (r *PostgresPasscodeAttemptRepository) ReleasePasscodeAttempt(reservation model.PasscodeReservation) error {
+	return model.ReleasePasscodeAttempt(r.db, reservation)
+}
+
+func (r *PostgresPasscodeAttemptRepository) ResetPasscodeFailures(key model.AttemptKey) error {
+	return model.ResetPasscodeFailures(r.db, key)
+}
+
+// PostgresEmailRepository adalah implementasi EmailRepository menggunakan PostgreSQL
+type PostgresEmailRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresEmailRepository digunakan untuk membuat EmailRepository berbasis PostgreSQL
+func NewPostgresEmailRepository(db *sql.DB) *PostgresEmailRepository {
+	return &PostgresEmailRepository{db: db}
+}
+
+func (r *PostgresEmailRepository) InsertEmail(email model.Email) error {
+	return model.InsertEmail(r.db, email)
+}
+
+// PostgresCartRepository adalah implementasi CartRepository menggunakan PostgreSQL
+type PostgresCartRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresCartRepository digunakan untuk membuat CartRepository berbasis PostgreSQL
+func NewPostgresCartRepository(db *sql.DB) *PostgresCartRepository {
+	return &PostgresCartRepository{db: db}
+}
+
+func (r *PostgresCartRepository) InsertCart(cart model.Cart) error {
+	return model.InsertCart(r.db, cart)
+}
+
+func (r *PostgresCartRepository) SelectCartByID(id string) (model.Cart, error) {
+	return model.SelectCartByID(r.db, id)
+}
+
+func (r *PostgresCartRepository) SelectCartByCustomerID(customerID string) (model.Cart, error) {
+	return model.SelectCartByCustomerID(r.db, customerID)
+}
+
+func (r *PostgresCartRepository) SelectCartItems(cartID string) ([]model.CartItem, error) {
+	return model.SelectCartItems(r.db, cartID)
+}
+
+func (r *PostgresCartRepository) UpsertCartItem(item model.CartItem) error {
+	return model.UpsertCartItem(r.db, item)
+}
+
+func (r *PostgresCartRepository) UpdateCartItemQuantity(cartID string, itemID string, quantity int32, now time.Time) error {
+	return model.UpdateCartItemQuantity(r.db, cartID, itemID, quantity, now)
+}
+
+func (r *PostgresCartRepository) DeleteCartItem(cartID string, itemID string, now time.Time) error {
+	return model.DeleteCartItem(r.db, cartID, itemID, now)
+}
+
+// PostgresIdempotencyRepository adalah implementasi IdempotencyRepository menggunakan PostgreSQL
+type PostgresIdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresIdempotencyRepository digunakan untuk membuat IdempotencyRepository berbasis PostgreSQL
+func NewPostgresIdempotencyRepository(db *sql.DB) *PostgresIdempotencyRepository {
+	return &PostgresIdempotencyRepository{db: db}
+}
+
+func (r *PostgresIdempotencyRepository) ReserveIdempotencyKey(key model.IdempotencyKey) (bool, error) {
+	return model.ReserveIdempotencyKey(r.db, key)
+}
+
+func (r *PostgresIdempotencyRepository) SelectIdempotencyKey(keyHash string) (model.IdempotencyKey, error) {
+	return model.SelectIdempotencyKey(r.db, keyHash)
+}
+
+func (r *PostgresIdempotencyRepository) ExtendIdempotencyKey(keyHash string, fingerprint string, lockedUntil time.Time) error {
+	return model.ExtendIdempotencyKey(r.db, keyHash, fingerprint, lockedUntil)
+}
+
+func (r *PostgresIdempotencyRepository) CompleteIdempotencyKey(keyHash string, fingerprint string, statusCode int, contentType string, response []byte) error {
+	return model.CompleteIdempotencyKey(r.db, keyHash, fingerprint, statusCode, contentType, response)
+}
+
+func (r *PostgresIdempotencyRepository) ReleaseIdempotencyKey(keyHash string, fingerprint string) error {
+	return model.ReleaseIdempotencyKey(r.db, keyHash, fingerprint)
+}
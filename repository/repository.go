@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+)
+
+// ProductRepository adalah kontrak akses data produk (beserta kategori dan varian) yang digunakan handler
+type ProductRepository interface {
+	SelectProduct(filter model.ProductFilter) ([]model.Product, int, error)
+	SelectProductByID(id string) (model.Product, error)
+	SelectProductIn(ids []string) ([]model.Product, error)
+	SearchProducts(keyword string, limit int) ([]model.ProductSearchResult, error)
+	InsertProduct(product model.Product, audit *model.AuditLog) error
+	UpdateProduct(product model.Product, audit *model.AuditLog) error
+	DeleteProduct(id string, audit *model.AuditLog) error
+
+	SelectProductCategoryIDs(productID string) ([]string, error)
+
+	SelectVariantByProductID(productID string) ([]model.ProductVariant, error)
+	SelectVariantIn(ids []string) ([]model.ProductVariant, error)
+	SelectProductIDsWithVariants(productIDs []string) (map[string]bool, error)
+	SelectVariantByID(productID string, id string) (model.ProductVariant, error)
+	InsertVariant(variant model.ProductVariant, audit *model.AuditLog) error
+	UpdateVariant(variant model.ProductVariant, audit *model.AuditLog) error
+	DeleteVariant(productID string, id string, audit *model.AuditLog) error
+}
+
+// CategoryRepository adalah kontrak akses data kategori produk yang digunakan handler
+type CategoryRepository interface {
+	SelectCategory() ([]model.Category, error)
+	SelectCategoryByID(id string) (model.Category, error)
+	InsertCategory(category model.Category, audit *model.AuditLog) error
+	UpdateCategory(category model.Category, audit *model.AuditLog) error
+	DeleteCategory(id string, audit *model.AuditLog) error
+}
+
+// PromoRepository adalah kontrak akses data kode promo yang digunakan handler admin
+type PromoRepository interface {
+	SelectPromoCode() ([]model.PromoCode, error)
+	SelectPromoCodeByID(id string) (model.PromoCode, error)
+	InsertPromoCode(promo model.PromoCode, audit *model.AuditLog) error
+	UpdatePromoCode(promo model.PromoCode, audit *model.AuditLog) error
+	DeactivatePromoCode(id string, audit *model.AuditLog) error
+}
+
+// OrderRepository adalah kontrak akses data pesanan yang digunakan handler.
+// Email notifikasi perubahan pesanan dimasukkan ke antrean melalui parameter email, di transaction yang sama
+// dengan perubahannya (nil berarti tanpa email).
+type OrderRepository interface {
+	CreateOrder(order model.Order, details []model.OrderDetail, discounts []model.OrderDiscount, cart *model.CartCheckout, email *model.Email) error
+	SelectOrderByID(id string) (model.Order, error)
+	SelectOrderByCustomerID(customerID string, page int, limit int) ([]model.Order, int, error)
+	SelectOrderDetailByOrderID(orderID string) ([]model.OrderDetail, error)
+	SelectOrderDiscountByOrderID(orderID string) ([]model.OrderDiscount, error)
+	SelectOrderStatusHistory(orderID string) ([]model.OrderStatusHistory, error)
+
+	UpdateOrderStatus(id string, confirmation model.Confirm, paidAt time.Time, email *model.Email) error
+	CancelUnpaidOrder(id string, reason string, cancelledAt time.Time, email *model.Email) error
+	ChangeOrderStatus(id string, to model.OrderStatus, note string, changedAt time.Time, audit *model.AuditLog, email *model.Email) error
+	UpdateOrderPasscode(id string, currentHash string, newHash string) error
+	AttachOrderToCustomer(orderID string, customerID string) error
+
+	InsertPasscodeResetToken(token model.PasscodeResetToken) error
+	CountPasscodeResetTokenSince(orderID string, since time.Time) (int, error)
+	RedeemPasscodeResetToken(orderID string, tokenHash string, passcodeHash string, now time.Time) error
+
+	ApplyPromoCode(code string, email string, details []model.OrderDetail, shippingFee int64, now time.Time) (model.OrderDiscount, error)
+}
+
+// AdminRepository adalah kontrak akses data akun admin yang digunakan middleware dan handler
+type AdminRepository interface {
+	SelectAdminUser() ([]model.AdminUser, error)
+	SelectAdminUserByID(id string) (model.AdminUser, error)
+	SelectAdminUserByEmail(email string) (model.AdminUser, error)
+	InsertAdminUser(admin model.AdminUser, audit *model.AuditLog) error
+	UpdateAdminUser(admin model.AdminUser, audit *model.AuditLog) error
+}
+
+// CustomerRepository adalah kontrak akses data akun pelanggan yang digunakan middleware dan handler
+type CustomerRepository interface {
+	SelectCustomerByID(id string) (model.Customer, error)
+	SelectCustomerByEmail(email string) (model.Customer, error)
+	InsertCustomer(customer model.Customer) error
+}
+
+// AuditRepository adalah kontrak akses data audit log perubahan oleh admin.
+// Audit log disimpan melalui parameter audit pada fungsi perubahan data di repository lain, di transaction yang
+// sama dengan perubahannya (nil berarti perubahan tidak dicatat).
+type AuditRepository interface {
+	SelectAuditLog(filter model.AuditFilter) ([]model.AuditLog, int, error)
+}
+
+// PasscodeAttemptRepository adalah kontrak akses data penghitung percobaan passcode yang gagal
+type PasscodeAttemptRepository interface {
+	ReservePasscodeAttempt(keys []model.AttemptKey, policy model.PasscodePolicy, now time.Time) (model.PasscodeReservation, error)
+	ReleasePasscodeAttempt(reservation model.PasscodeReservation) error
+	ResetPasscodeFailures(key model.AttemptKey) error
+}
+
+// EmailRepository adalah kontrak akses antrean email yang akan dikirim
+type EmailRepository interface {
+	InsertEmail(email model.Email) error
+}
+
+// CartRepository adalah kontrak akses data keranjang belanja
+type CartRepository interface {
+	InsertCart(cart model.Cart) error
+	SelectCartByID(id string) (model.Cart, error)
+	SelectCartByCustomerID(customerID string) (model.Cart, error)
+	SelectCartItems(cartID string) ([]model.CartItem, error)
+	UpsertCartItem(item model.CartItem) error
+	UpdateCartItemQuantity(cartID string, itemID string, quantity int32, now time.Time) error
+	DeleteCartItem(cartID string, itemID string, now time.Time) error
+}
+
+// IdempotencyRepository adalah kontrak akses data Idempotency-Key beserta response yang disimpan
+type IdempotencyRepository interface {
+	ReserveIdempotencyKey(key model.IdempotencyKey) (bool, error)
+	SelectIdempotencyKey(keyHash string) (model.IdempotencyKey, error)
+	ExtendIdempotencyKey(keyHash string, fingerprint string, lockedUntil time.Time) error
+	CompleteIdempotencyKey(keyHash string, fingerprint string, statusCode int, contentType string, response []byte) error
+	ReleaseIdempotencyKey(keyHash string, fingerprint string) error
+}
@@ -0,0 +1,109 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/fastcampus-backend-golang/online-shop/i18n"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// nama field pada error validasi memakai nama JSON (atau form untuk query), bukan nama field struct
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// fieldName digunakan untuk mengambil nama field dari tag json atau form
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+// BindError digunakan untuk mengirim error ketika request gagal di-bind oleh gin,
+// beserta kesalahan per field dari validasi tag binding atau tipe data JSON yang tidak sesuai
+func BindError(c *gin.Context, base *model.Error, err error) {
+	Error(c, base.WithFields(bindingFields(err)...))
+}
+
+// bindingFields digunakan untuk mengubah error binding menjadi kesalahan per field
+func bindingFields(err error) []model.FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]model.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			code := fe.Tag()
+			if code == "len" && fe.Param() == "0" {
+				code = "readonly"
+			}
+			if !i18n.Has("field." + code) {
+				code = "invalid"
+			}
+
+			field := model.FieldError{Field: fieldPath(fe.Namespace()), Code: code}
+			if fe.Param() != "" && code != "readonly" {
+				field.Params = map[string]any{"param": fe.Param()}
+			}
+			field.Message = i18n.Text(i18n.DefaultLanguage, "field."+code, field.Params, "")
+			fields = append(fields, field)
+		}
+
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		field := model.FieldError{Field: typeErr.Field, Code: "type", Params: map[string]any{"type": jsonType(typeErr.Type)}}
+		field.Message = i18n.Text(i18n.DefaultLanguage, "field.type", field.Params, "")
+		return []model.FieldError{field}
+	}
+
+	return nil
+}
+
+// jsonType digunakan untuk mengambil nama tipe JSON dari tipe Go tujuan unmarshal
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// fieldPath digunakan untuk menghapus nama struct di awal namespace validator, misalnya Product.price menjadi price
+func fieldPath(namespace string) string {
+	_, path, ok := strings.Cut(namespace, ".")
+	if !ok {
+		return namespace
+	}
+
+	return path
+}
@@ -0,0 +1,111 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fastcampus-backend-golang/online-shop/i18n"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType adalah content type RFC 7807 yang dipakai jika client memintanya di header Accept
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix adalah awalan URI jenis error (member "type" RFC 7807), diikuti kode error
+const problemTypePrefix = "urn:online-shop:error:"
+
+// requestIDHeader adalah header ID request yang diisi middleware RequestID pada response
+const requestIDHeader = "X-Request-ID"
+
+// statusByKind adalah pemetaan jenis error ke status HTTP
+var statusByKind = map[model.ErrorKind]int{
+	model.ErrorKindValidation:      400,
+	model.ErrorKindUnauthorized:    401,
+	model.ErrorKindForbidden:       403,
+	model.ErrorKindNotFound:        404,
+	model.ErrorKindConflict:        409,
+	model.ErrorKindGone:            410,
+	model.ErrorKindUnprocessable:   422,
+	model.ErrorKindTooManyRequests: 429,
+	model.ErrorKindInternal:        500,
+}
+
+// Error digunakan untuk mengirim error dalam format yang sama di seluruh API:
+//
+//	{"type": "urn:online-shop:error:order_not_found", "title": "Pesanan tidak ditemukan", "status": 404,
+//	 "code": "order_not_found", "error": "Pesanan tidak ditemukan", "fields": [...], "requestId": "..."}
+//
+// Error selain *model.Error dianggap kesalahan server, dicatat ke log, dan tidak ditampilkan ke client.
+func Error(c *gin.Context, err error) {
+	var apiErr *model.Error
+	if !errors.As(err, &apiErr) {
+		fmt.Printf("Terjadi kesalahan pada %s %s (request %s): %v\n", c.Request.Method, c.Request.URL.Path, requestID(c), err)
+		apiErr = model.ErrInternal
+	}
+
+	status, ok := statusByKind[apiErr.Kind]
+	if !ok {
+		status = 500
+	}
+
+	// pesan ditampilkan dalam bahasa request, kode error tidak diterjemahkan
+	language := i18n.Language(c)
+	message := i18n.Text(language, "error."+apiErr.Code, nil, apiErr.Message)
+
+	// data tambahan ditampilkan sebagai extension member RFC 7807
+	body := gin.H{}
+	for key, value := range apiErr.Extra {
+		body[key] = value
+	}
+
+	body["type"] = problemTypePrefix + apiErr.Code
+	body["title"] = message
+	body["status"] = status
+	body["instance"] = c.Request.URL.Path
+	body["code"] = apiErr.Code
+	body["error"] = message // dipertahankan untuk client lama
+	if len(apiErr.Fields) > 0 {
+		body["fields"] = localizeFields(language, apiErr.Fields)
+	}
+	if id := requestID(c); id != "" {
+		body["requestId"] = id
+	}
+
+	// gunakan application/problem+json jika diminta client, selain itu application/json
+	if strings.Contains(c.GetHeader("Accept"), ProblemContentType) {
+		data, err := json.Marshal(body)
+		if err == nil {
+			c.Data(status, ProblemContentType, data)
+			return
+		}
+	}
+
+	c.JSON(status, body)
+}
+
+// Abort digunakan di middleware untuk mengirim error lalu menghentikan handler selanjutnya
+func Abort(c *gin.Context, err error) {
+	Error(c, err)
+	c.Abort()
+}
+
+// localizeFields digunakan untuk menerjemahkan pesan kesalahan per field berdasarkan kodenya
+func localizeFields(language string, fields []model.FieldError) []model.FieldError {
+	localized := make([]model.FieldError, len(fields))
+	for i, field := range fields {
+		if field.Code != "" {
+			field.Message = i18n.Text(language, "field."+field.Code, field.Params, field.Message)
+		}
+		localized[i] = field
+	}
+
+	return localized
+}
+
+// requestID digunakan untuk mengambil ID request dari header response
+func requestID(c *gin.Context) string {
+	return c.Writer.Header().Get(requestIDHeader)
+}
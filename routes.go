@@ -3,37 +3,153 @@ package main
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/fastcampus-backend-golang/online-shop/config"
 	"github.com/fastcampus-backend-golang/online-shop/handler"
+	"github.com/fastcampus-backend-golang/online-shop/mail"
 	"github.com/fastcampus-backend-golang/online-shop/middleware"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+	"github.com/fastcampus-backend-golang/online-shop/repository"
+	"github.com/fastcampus-backend-golang/online-shop/response"
 
 	"github.com/gin-gonic/gin"
 )
 
 // routes digunakan untuk inisiasi endpoint-endpoint API
-func routes(db *sql.DB) (http.Handler, error) {
+func routes(cfg config.Config, db *sql.DB) (http.Handler, error) {
 	// pastikan koneksi ke database tidak nil
 	if db == nil {
 		return nil, errors.New("tidak ada koneksi ke database")
 	}
 
+	// init repository
+	products := repository.NewPostgresProductRepository(db)
+	categories := repository.NewPostgresCategoryRepository(db)
+	promos := repository.NewPostgresPromoRepository(db)
+	orders := repository.NewPostgresOrderRepository(db)
+	audits := repository.NewPostgresAuditRepository(db)
+	passcodeAttempts := repository.NewPostgresPasscodeAttemptRepository(db)
+	emails := repository.NewPostgresEmailRepository(db)
+	carts := repository.NewPostgresCartRepository(db)
+	idempotencyKeys := repository.NewPostgresIdempotencyRepository(db)
+	admins := repository.NewPostgresAdminRepository(db)
+	customers := repository.NewPostgresCustomerRepository(db)
+
+	// init pembatas percobaan passcode pesanan
+	guard := handler.NewPasscodeGuard(passcodeAttempts, model.PasscodePolicy{
+		MaxOrderAttempts: cfg.Passcode.MaxOrderAttempts,
+		MaxIPAttempts:    cfg.Passcode.MaxIPAttempts,
+		BaseBackoff:      cfg.Passcode.Backoff,
+		Lockout:          cfg.Passcode.Lockout,
+	})
+
+	// init notifikasi pesanan melalui antrean email (dikirim oleh worker)
+	templates, err := mail.LoadTemplates(cfg.Mail.Locale)
+	if err != nil {
+		return nil, err
+	}
+	notifier := handler.NewOrderNotifier(emails, templates, cfg.Mail.Locale)
+
+	// init middleware admin per peran
+	catalogManager := middleware.AdminOnly(admins, cfg.Admin.Secret, model.AdminRoleCatalogManager)
+	orderManager := middleware.AdminOnly(admins, cfg.Admin.Secret, model.AdminRoleOrderManager)
+	superAdmin := middleware.AdminOnly(admins, cfg.Admin.Secret, model.AdminRoleSuperAdmin)
+
+	// init middleware pelanggan, token pelanggan memakai kunci yang sama dengan admin dengan audience berbeda
+	customerOnly := middleware.CustomerOnly(customers, cfg.Admin.Secret)
+	optionalCustomer := middleware.OptionalCustomer(customers, cfg.Admin.Secret)
+
+	// init middleware Idempotency-Key agar checkout dan konfirmasi pembayaran aman diulang
+	idempotent := middleware.Idempotency(idempotencyKeys, cfg.Order.IdempotencyTTL, handler.CartTokenHeader)
+
 	// init router
 	r := gin.Default()
 
+	// IP client (batas percobaan passcode dan audit log) hanya diambil dari X-Forwarded-For jika dikirim proxy terpercaya
+	if err := r.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("HTTP_TRUSTED_PROXIES tidak valid: %w", err)
+	}
+
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Language())
+
+	// endpoint yang tidak ada dijawab dengan format error yang sama
+	r.NoRoute(func(c *gin.Context) {
+		response.Error(c, model.ErrRouteNotFound)
+	})
+
 	// endpoint publik
-	r.GET("/api/v1/products", handler.ListProducts(db))
-	r.GET("/api/v1/products/:id", handler.GetProduct(db))
-	r.POST("/api/v1/checkout", handler.CheckoutOrder(db))
+	r.GET("/api/v1/products", handler.ListProducts(products))
+	r.GET("/api/v1/products/search", handler.SearchProducts(products))
+	r.GET("/api/v1/products/:id", handler.GetProduct(products))
+	r.GET("/api/v1/categories", handler.ListCategories(categories))
+	r.POST("/api/v1/checkout", optionalCustomer, idempotent, handler.CheckoutOrder(products, orders, carts, notifier, cfg.Order, cfg.Passcode))
+
+	// endpoint keranjang (tamu dengan header X-Cart-Token atau pelanggan yang login)
+	r.POST("/api/v1/carts", optionalCustomer, handler.CreateCart(carts, products))
+	r.GET("/api/v1/carts/:id", optionalCustomer, handler.GetCart(carts, products))
+	r.POST("/api/v1/carts/:id/items", optionalCustomer, handler.AddCartItem(carts, products, cfg.Order))
+	r.PUT("/api/v1/carts/:id/items/:itemId", optionalCustomer, handler.UpdateCartItem(carts, products, cfg.Order))
+	r.DELETE("/api/v1/carts/:id/items/:itemId", optionalCustomer, handler.DeleteCartItem(carts, products))
+
+	// endpoint akun pelanggan
+	r.POST("/api/v1/customers/register", handler.RegisterCustomer(customers))
+	r.POST("/api/v1/customers/login", handler.CustomerLogin(customers, cfg.Admin.Secret, cfg.Customer))
+	r.GET("/api/v1/me", customerOnly, handler.GetCurrentCustomer())
+	r.GET("/api/v1/me/orders", customerOnly, handler.ListCustomerOrders(orders))
+	r.POST("/api/v1/me/orders/attach", customerOnly, handler.AttachCustomerOrder(orders, guard))
 
 	// endpoint pelanggan dengan passcode
-	r.POST("/api/v1/orders/:id/confirm", handler.ConfirmOrder(db))
-	r.GET("/api/v1/orders/:id", handler.GetOrder(db))
+	r.POST("/api/v1/orders/:id/confirm", idempotent, handler.ConfirmOrder(orders, guard, notifier))
+	r.GET("/api/v1/orders/:id", handler.GetOrder(orders, guard))
+	r.POST("/api/v1/orders/:id/cancel", handler.CancelOrder(orders, guard, notifier))
+	r.POST("/api/v1/orders/:id/passcode/rotate", handler.RotatePasscode(orders, guard, cfg.Passcode))
+	r.POST("/api/v1/orders/:id/passcode/recover", handler.RecoverPasscode(orders, notifier, cfg.Passcode))
+	r.POST("/api/v1/orders/:id/passcode/reset", handler.ResetPasscode(orders, guard, cfg.Passcode))
+
+	// endpoint login admin
+	r.POST("/admin/login", handler.AdminLogin(admins, cfg.Admin))
 
-	// endpoint admin (dengan verifikasi header)
-	r.POST("/admin/products", middleware.AdminOnly(), handler.CreateProduct(db))
-	r.PUT("/admin/products/:id", middleware.AdminOnly(), handler.UpdateProduct(db))
-	r.DELETE("/admin/products/:id", middleware.AdminOnly(), handler.DeleteProduct(db))
+	// endpoint admin (dengan verifikasi token dan peran)
+	r.POST("/admin/products", catalogManager, handler.CreateProduct(products))
+	r.PUT("/admin/products/:id", catalogManager, handler.UpdateProduct(products))
+	r.DELETE("/admin/products/:id", catalogManager, handler.DeleteProduct(products))
+	r.POST("/admin/products/:id/variants", catalogManager, handler.CreateVariant(products))
+	r.PUT("/admin/products/:id/variants/:variantId", catalogManager, handler.UpdateVariant(products))
+	r.DELETE("/admin/products/:id/variants/:variantId", catalogManager, handler.DeleteVariant(products))
+	r.POST("/admin/categories", catalogManager, handler.CreateCategory(categories))
+	r.PUT("/admin/categories/:id", catalogManager, handler.UpdateCategory(categories))
+	r.DELETE("/admin/categories/:id", catalogManager, handler.DeleteCategory(categories))
+	r.GET("/admin/promos", catalogManager, handler.ListPromoCodes(promos))
+	r.POST("/admin/promos", catalogManager, handler.CreatePromoCode(promos))
+	r.PUT("/admin/promos/:id", catalogManager, handler.UpdatePromoCode(promos))
+	r.DELETE("/admin/promos/:id", catalogManager, handler.DeletePromoCode(promos))
+	r.POST("/admin/orders/:id/ship", orderManager, handler.ChangeOrderStatus(orders, notifier, model.OrderStatusShipped))
+	r.POST("/admin/orders/:id/deliver", orderManager, handler.ChangeOrderStatus(orders, notifier, model.OrderStatusDelivered))
+	r.POST("/admin/orders/:id/cancel", orderManager, handler.ChangeOrderStatus(orders, notifier, model.OrderStatusCancelled))
+	r.GET("/admin/users", superAdmin, handler.ListAdminUsers(admins))
+	r.POST("/admin/users", superAdmin, handler.CreateAdminUser(admins))
+	r.PUT("/admin/users/:id", superAdmin, handler.UpdateAdminUser(admins))
+	r.GET("/admin/audit", superAdmin, handler.ListAuditLogs(audits))
 
 	return r, nil
 }
+
+// maxEmailBackoff adalah jeda terlama antar percobaan ulang pengiriman email
+const maxEmailBackoff = time.Hour
+
+// mailSender digunakan untuk memilih pengirim email: file jika MAIL_DIR diisi (mode pengembangan),
+// SMTP jika SMTP_HOST diisi, selain itu email hanya dicetak ke log (MAIL_LOG_ONLY, divalidasi di config)
+func mailSender(cfg config.MailConfig) mail.Sender {
+	switch {
+	case cfg.Dir != "":
+		return mail.NewFileSender(cfg.Dir, cfg.From)
+	case cfg.SMTPHost != "":
+		return mail.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From, cfg.SMTPInsecure)
+	default:
+		return mail.LogSender{}
+	}
+}
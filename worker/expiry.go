@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+)
+
+// ExpireOrders digunakan untuk menandai pesanan yang tidak dibayar tepat waktu secara berkala
+// hingga context dibatalkan
+func ExpireOrders(ctx context.Context, db *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// tandai pesanan kedaluwarsa dan kembalikan stoknya
+			count, err := model.ExpireOverdueOrders(db, time.Now())
+			if err != nil {
+				fmt.Printf("Gagal menandai pesanan kedaluwarsa: %v\n", err)
+				continue
+			}
+
+			if count > 0 {
+				fmt.Printf("%d pesanan ditandai kedaluwarsa\n", count)
+			}
+		}
+	}
+}
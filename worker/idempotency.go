@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/model"
+)
+
+// PurgeIdempotencyKeys digunakan untuk menghapus Idempotency-Key beserta response yang sudah kedaluwarsa
+// secara berkala hingga context dibatalkan
+func PurgeIdempotencyKeys(ctx context.Context, db *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := model.DeleteExpiredIdempotencyKeys(db, time.Now()); err != nil {
+				fmt.Printf("Gagal menghapus Idempotency-Key kedaluwarsa: %v\n", err)
+			}
+		}
+	}
+}
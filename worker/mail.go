@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fastcampus-backend-golang/online-shop/mail"
+	"github.com/fastcampus-backend-golang/online-shop/model"
+)
+
+const (
+	emailBatchSize   = 20               // jumlah email yang diambil dari antrean setiap pemeriksaan
+	emailSendTimeout = 30 * time.Second // batas waktu pengiriman satu email
+)
+
+// SendEmails digunakan untuk mengirim email dari antrean secara berkala hingga context dibatalkan.
+// Email yang gagal dicoba ulang dengan jeda yang berlipat dua hingga batas percobaan tercapai.
+func SendEmails(ctx context.Context, db *sql.DB, sender mail.Sender, policy model.EmailRetryPolicy, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// ambil email yang siap dikirim, dikunci selama batas waktu pengiriman seluruh batch
+			emails, err := model.ClaimPendingEmails(db, time.Now(), emailBatchSize, policy.MaxAttempts, emailBatchSize*emailSendTimeout)
+			if err != nil {
+				fmt.Printf("Gagal mengambil antrean email: %v\n", err)
+				continue
+			}
+
+			for _, email := range emails {
+				sendEmail(ctx, db, sender, policy, email)
+			}
+		}
+	}
+}
+
+// sendEmail digunakan untuk mengirim satu email lalu mencatat hasilnya di antrean
+func sendEmail(ctx context.Context, db *sql.DB, sender mail.Sender, policy model.EmailRetryPolicy, email model.Email) {
+	sendCtx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+	defer cancel()
+
+	err := sender.Send(sendCtx, mail.Message{
+		To:      email.To,
+		Subject: email.Subject,
+		Text:    email.Text,
+		HTML:    email.HTML,
+	})
+
+	now := time.Now()
+	if err == nil {
+		if err := model.MarkEmailSent(db, email.ID, now); err != nil {
+			fmt.Printf("Gagal menandai email %s terkirim: %v\n", email.ID, err)
+		}
+		return
+	}
+
+	// pengiriman yang dihentikan karena server berhenti tidak dihitung sebagai percobaan,
+	// email diambil lagi setelah masa klaimnya berakhir
+	if ctx.Err() != nil {
+		fmt.Printf("Pengiriman email %s dihentikan: %v\n", email.ID, err)
+		return
+	}
+
+	// catat kegagalan dan jadwalkan percobaan berikutnya
+	attempts := email.Attempts + 1
+	if attempts >= policy.MaxAttempts {
+		fmt.Printf("Email %s ke %s gagal dikirim setelah %d percobaan: %v\n", email.ID, email.To, attempts, err)
+	} else {
+		fmt.Printf("Email %s ke %s gagal dikirim (percobaan %d): %v\n", email.ID, email.To, attempts, err)
+	}
+
+	if err := model.MarkEmailFailed(db, email.ID, err.Error(), policy.NextAttempt(attempts, now)); err != nil {
+		fmt.Printf("Gagal mencatat kegagalan email %s: %v\n", email.ID, err)
+	}
+}